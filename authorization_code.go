@@ -0,0 +1,171 @@
+package warrant
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/documents"
+	"github.com/pivotal-cf-experimental/warrant/internal/network"
+)
+
+// AuthorizationCodeRequest describes a request to UAA for an authorization code
+// using the "authorization_code" grant type.
+type AuthorizationCodeRequest struct {
+	// ClientID is the unique identifier of the client requesting the code.
+	ClientID string
+
+	// RedirectURI is the location to which UAA will redirect the user agent
+	// with the authorization code. It must match one of the redirect URIs
+	// registered for the client.
+	RedirectURI string
+
+	// Scopes are the permission values requested for the token.
+	Scopes []string
+
+	// State is an opaque value that UAA returns unmodified with the
+	// authorization code. It should be generated using NewState and checked
+	// when the user agent is redirected back to the client to protect against
+	// cross-site request forgery.
+	State string
+
+	// CodeChallenge is the PKCE code challenge derived from the code verifier
+	// that will be given when exchanging the code. It can be generated from a
+	// code verifier using NewCodeChallenge.
+	CodeChallenge string
+
+	// CodeChallengeMethod is the method used to derive the code challenge. This
+	// value defaults to "S256" when a code challenge is given.
+	CodeChallengeMethod string
+}
+
+// AuthorizationCodeExchange describes a request to UAA to exchange an
+// authorization code for tokens.
+type AuthorizationCodeExchange struct {
+	// Code is the authorization code returned by UAA.
+	Code string
+
+	// RedirectURI is the redirect URI given when requesting the code.
+	RedirectURI string
+
+	// CodeVerifier is the PKCE code verifier from which the code challenge
+	// given when requesting the code was derived.
+	CodeVerifier string
+
+	// ClientID is the unique identifier of the client that requested the code.
+	ClientID string
+
+	// ClientSecret is the secret of the client that requested the code. This
+	// value is empty for public clients relying upon PKCE.
+	ClientSecret string
+}
+
+// NewState returns a random value suitable for use as the state of an
+// AuthorizationCodeRequest.
+func NewState() (string, error) {
+	return randomString(16)
+}
+
+// NewCodeVerifier returns a random PKCE code verifier as described in RFC 7636.
+func NewCodeVerifier() (string, error) {
+	return randomString(32)
+}
+
+// NewCodeChallenge returns the PKCE code challenge for the given code verifier
+// using the "S256" method.
+func NewCodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString(size int) (string, error) {
+	b := make([]byte, size)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthorizeURL returns the URL of the UAA authorization endpoint to which a
+// user agent should be directed to request an authorization code.
+func (ts TokensService) AuthorizeURL(request AuthorizationCodeRequest) string {
+	query := url.Values{
+		"response_type": []string{"code"},
+		"client_id":     []string{request.ClientID},
+	}
+
+	if request.RedirectURI != "" {
+		query.Set("redirect_uri", request.RedirectURI)
+	}
+
+	if len(request.Scopes) > 0 {
+		query.Set("scope", strings.Join(request.Scopes, " "))
+	}
+
+	if request.State != "" {
+		query.Set("state", request.State)
+	}
+
+	if request.CodeChallenge != "" {
+		method := request.CodeChallengeMethod
+		if method == "" {
+			method = "S256"
+		}
+
+		query.Set("code_challenge", request.CodeChallenge)
+		query.Set("code_challenge_method", method)
+	}
+
+	return strings.TrimSuffix(ts.config.Host, "/") + "/oauth/authorize?" + query.Encode()
+}
+
+// ExchangeAuthorizationCode will make a request to UAA to exchange an authorization code
+// for tokens using the "authorization_code" grant type.
+func (ts TokensService) ExchangeAuthorizationCode(exchange AuthorizationCodeExchange) (TokenResponse, error) {
+	return ts.ExchangeAuthorizationCodeWithContext(context.Background(), exchange)
+}
+
+// ExchangeAuthorizationCodeWithContext is like ExchangeAuthorizationCode, but uses the given
+// context for the request to UAA.
+func (ts TokensService) ExchangeAuthorizationCodeWithContext(ctx context.Context, exchange AuthorizationCodeExchange) (TokenResponse, error) {
+	values := url.Values{
+		"client_id":  []string{exchange.ClientID},
+		"grant_type": []string{"authorization_code"},
+		"code":       []string{exchange.Code},
+	}
+
+	if exchange.RedirectURI != "" {
+		values.Set("redirect_uri", exchange.RedirectURI)
+	}
+
+	if exchange.CodeVerifier != "" {
+		values.Set("code_verifier", exchange.CodeVerifier)
+	}
+
+	resp, err := newNetworkClient(ts.config).MakeRequest(network.Request{
+		Method:                "POST",
+		Path:                  "/oauth/token",
+		Authorization:         network.NewBasicAuthorization(exchange.ClientID, exchange.ClientSecret),
+		Body:                  network.NewFormRequestBody(values),
+		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
+	})
+	if err != nil {
+		return TokenResponse{}, translateError(err)
+	}
+
+	var response documents.TokenResponse
+	err = json.Unmarshal(resp.Body, &response)
+	if err != nil {
+		return TokenResponse{}, MalformedResponseError{err}
+	}
+
+	return newTokenResponseFromDocument(response), nil
+}
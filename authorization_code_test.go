@@ -0,0 +1,211 @@
+package warrant_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pivotal-cf-experimental/warrant"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Authorization code grant", func() {
+	var (
+		service warrant.TokensService
+		config  warrant.Config
+		user    warrant.User
+		client  warrant.Client
+	)
+
+	authorize := func(authorizeURL string) *url.URL {
+		request, err := http.NewRequest("POST", authorizeURL, strings.NewReader(url.Values{
+			"username": {"username"},
+			"password": {"password"},
+			"source":   {"credentials"},
+		}.Encode()))
+		Expect(err).NotTo(HaveOccurred())
+
+		request.Header.Set("Accept", "application/json")
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		httpClient := &http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+
+		response, err := httpClient.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+		Expect(response.StatusCode).To(Equal(http.StatusFound))
+
+		location, err := url.Parse(response.Header.Get("Location"))
+		Expect(err).NotTo(HaveOccurred())
+
+		return location
+	}
+
+	BeforeEach(func() {
+		config = warrant.Config{
+			Host:          fakeUAA.URL(),
+			SkipVerifySSL: true,
+			TraceWriter:   TraceWriter,
+		}
+		service = warrant.NewTokensService(config)
+
+		clientsService := warrant.NewClientsService(config)
+		adminToken, err := clientsService.GetToken("admin", "admin")
+		Expect(err).NotTo(HaveOccurred())
+
+		client = warrant.Client{
+			ID:                   "web-app",
+			Scope:                []string{"openid"},
+			AuthorizedGrantTypes: []string{"authorization_code", "refresh_token"},
+			RedirectURI:          []string{"https://app.example.com/callback"},
+			Autoapprove:          []string{"openid"},
+		}
+		err = clientsService.Create(client, "web-app-secret", adminToken)
+		Expect(err).NotTo(HaveOccurred())
+
+		usersService := warrant.NewUsersService(config)
+		user, err = usersService.Create("username", "user@example.com", adminToken)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = usersService.SetPassword(user.ID, "password", adminToken)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("AuthorizeURL", func() {
+		It("builds the URL of the authorization endpoint", func() {
+			authorizeURL, err := url.Parse(service.AuthorizeURL(warrant.AuthorizationCodeRequest{
+				ClientID:      "web-app",
+				RedirectURI:   "https://app.example.com/callback",
+				Scopes:        []string{"openid", "profile"},
+				State:         "some-state",
+				CodeChallenge: "some-challenge",
+			}))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(authorizeURL.Scheme + "://" + authorizeURL.Host).To(Equal(fakeUAA.URL()))
+			Expect(authorizeURL.Path).To(Equal("/oauth/authorize"))
+			Expect(authorizeURL.Query()).To(Equal(url.Values{
+				"response_type":         {"code"},
+				"client_id":             {"web-app"},
+				"redirect_uri":          {"https://app.example.com/callback"},
+				"scope":                 {"openid profile"},
+				"state":                 {"some-state"},
+				"code_challenge":        {"some-challenge"},
+				"code_challenge_method": {"S256"},
+			}))
+		})
+	})
+
+	Describe("NewCodeChallenge", func() {
+		It("derives the S256 code challenge from the code verifier", func() {
+			// This example is given in Appendix B of RFC 7636.
+			challenge := warrant.NewCodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+			Expect(challenge).To(Equal("E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"))
+		})
+	})
+
+	Describe("ExchangeAuthorizationCode", func() {
+		var (
+			codeVerifier string
+			state        string
+		)
+
+		BeforeEach(func() {
+			var err error
+			codeVerifier, err = warrant.NewCodeVerifier()
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err = warrant.NewState()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("exchanges the authorization code for tokens", func() {
+			location := authorize(service.AuthorizeURL(warrant.AuthorizationCodeRequest{
+				ClientID:      "web-app",
+				RedirectURI:   "https://app.example.com/callback",
+				Scopes:        []string{"openid"},
+				State:         state,
+				CodeChallenge: warrant.NewCodeChallenge(codeVerifier),
+			}))
+			Expect(location.Host).To(Equal("app.example.com"))
+			Expect(location.Path).To(Equal("/callback"))
+			Expect(location.Query().Get("state")).To(Equal(state))
+
+			response, err := service.ExchangeAuthorizationCode(warrant.AuthorizationCodeExchange{
+				Code:         location.Query().Get("code"),
+				RedirectURI:  "https://app.example.com/callback",
+				CodeVerifier: codeVerifier,
+				ClientID:     "web-app",
+				ClientSecret: "web-app-secret",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.RefreshToken).NotTo(BeEmpty())
+			Expect(response.Scopes).To(Equal([]string{"openid"}))
+
+			token, err := service.Decode(response.AccessToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.UserID).To(Equal(user.ID))
+			Expect(token.ClientID).To(Equal("web-app"))
+			Expect(token.Issuer).To(Equal(fakeUAA.URL() + "/oauth/token"))
+		})
+
+		Context("failure cases", func() {
+			var location *url.URL
+
+			BeforeEach(func() {
+				location = authorize(service.AuthorizeURL(warrant.AuthorizationCodeRequest{
+					ClientID:      "web-app",
+					RedirectURI:   "https://app.example.com/callback",
+					Scopes:        []string{"openid"},
+					State:         state,
+					CodeChallenge: warrant.NewCodeChallenge(codeVerifier),
+				}))
+			})
+
+			It("returns an error when the code verifier does not match the code challenge", func() {
+				_, err := service.ExchangeAuthorizationCode(warrant.AuthorizationCodeExchange{
+					Code:         location.Query().Get("code"),
+					RedirectURI:  "https://app.example.com/callback",
+					CodeVerifier: "some-other-verifier",
+					ClientID:     "web-app",
+					ClientSecret: "web-app-secret",
+				})
+				Expect(err).To(BeAssignableToTypeOf(warrant.BadRequestError{}))
+			})
+
+			It("returns an error when the redirect URI does not match", func() {
+				_, err := service.ExchangeAuthorizationCode(warrant.AuthorizationCodeExchange{
+					Code:         location.Query().Get("code"),
+					RedirectURI:  "https://evil.example.com/callback",
+					CodeVerifier: codeVerifier,
+					ClientID:     "web-app",
+					ClientSecret: "web-app-secret",
+				})
+				Expect(err).To(BeAssignableToTypeOf(warrant.BadRequestError{}))
+			})
+
+			It("returns an error when the code has already been used", func() {
+				exchange := warrant.AuthorizationCodeExchange{
+					Code:         location.Query().Get("code"),
+					RedirectURI:  "https://app.example.com/callback",
+					CodeVerifier: codeVerifier,
+					ClientID:     "web-app",
+					ClientSecret: "web-app-secret",
+				}
+
+				_, err := service.ExchangeAuthorizationCode(exchange)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = service.ExchangeAuthorizationCode(exchange)
+				Expect(err).To(BeAssignableToTypeOf(warrant.BadRequestError{}))
+				Expect(err).To(MatchError(ContainSubstring("invalid_grant")))
+			})
+		})
+	})
+})
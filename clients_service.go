@@ -1,6 +1,7 @@
 package warrant
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -10,7 +11,6 @@ import (
 	"github.com/pivotal-cf-experimental/warrant/internal/network"
 )
 
-// TODO: Pagination for List
 // TODO: Change Secret
 // TODO: Batch Create
 // TODO: Batch Update
@@ -34,12 +34,23 @@ func NewClientsService(config Config) ClientsService {
 // Create will make a request to UAA to register a client with the given client resource and
 // A token with the "clients.write" or "clients.admin" scope is required.
 func (cs ClientsService) Create(client Client, secret, token string) error {
-	_, err := newNetworkClient(cs.config).MakeRequest(network.Request{
-		Method:        "POST",
-		Path:          "/oauth/clients",
-		Authorization: network.NewTokenAuthorization(token),
-		Body:          network.NewJSONRequestBody(client.toDocument(secret)),
+	return cs.CreateWithContext(context.Background(), client, secret, token)
+}
+
+// CreateWithContext is like Create, but uses the given context for the request to UAA.
+func (cs ClientsService) CreateWithContext(ctx context.Context, client Client, secret, token string) error {
+	authorization, err := tokenAuthorization(ctx, cs.config, token)
+	if err != nil {
+		return err
+	}
+
+	_, err = newNetworkClient(cs.config).MakeRequest(network.Request{
+		Method:                "POST",
+		Path:                  "/oauth/clients",
+		Authorization:         authorization,
+		Body:                  network.NewJSONRequestBody(client.toDocument(secret)),
 		AcceptableStatusCodes: []int{http.StatusCreated},
+		Context:               ctx,
 	})
 	if err != nil {
 		return translateError(err)
@@ -51,11 +62,22 @@ func (cs ClientsService) Create(client Client, secret, token string) error {
 // Get will make a request to UAA to fetch the client matching the given id.
 // A token with the "clients.read" scope is required.
 func (cs ClientsService) Get(id, token string) (Client, error) {
+	return cs.GetWithContext(context.Background(), id, token)
+}
+
+// GetWithContext is like Get, but uses the given context for the request to UAA.
+func (cs ClientsService) GetWithContext(ctx context.Context, id, token string) (Client, error) {
+	authorization, err := tokenAuthorization(ctx, cs.config, token)
+	if err != nil {
+		return Client{}, err
+	}
+
 	resp, err := newNetworkClient(cs.config).MakeRequest(network.Request{
 		Method:                "GET",
 		Path:                  fmt.Sprintf("/oauth/clients/%s", id),
-		Authorization:         network.NewTokenAuthorization(token),
+		Authorization:         authorization,
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return Client{}, translateError(err)
@@ -73,28 +95,53 @@ func (cs ClientsService) Get(id, token string) (Client, error) {
 // List will make a request to UAA to retrieve all client resources matching the given query.
 // A token with the "clients.read" or "clients.admin" scope is required.
 func (cs ClientsService) List(query Query, token string) ([]Client, error) {
+	return cs.ListWithContext(context.Background(), query, token)
+}
+
+// ListWithContext is like List, but uses the given context for the request to UAA.
+func (cs ClientsService) ListWithContext(ctx context.Context, query Query, token string) ([]Client, error) {
+	list, _, err := cs.ListPageWithContext(ctx, query, token)
+	if err != nil {
+		return []Client{}, err
+	}
+
+	return list, nil
+}
+
+// ListPage will make a request to UAA to retrieve a single page of client resources matching the
+// given query. The returned Page describes where that page sits within the full set of results.
+// Use Iterate to walk every page of results.
+func (cs ClientsService) ListPage(query Query, token string) ([]Client, Page, error) {
+	return cs.ListPageWithContext(context.Background(), query, token)
+}
+
+// ListPageWithContext is like ListPage, but uses the given context for the request to UAA.
+func (cs ClientsService) ListPageWithContext(ctx context.Context, query Query, token string) ([]Client, Page, error) {
 	requestPath := url.URL{
-		Path: "/oauth/clients",
-		RawQuery: url.Values{
-			"filter": []string{query.Filter},
-			"sortBy": []string{query.SortBy},
-		}.Encode(),
+		Path:     "/oauth/clients",
+		RawQuery: query.toValues().Encode(),
+	}
+
+	authorization, err := tokenAuthorization(ctx, cs.config, token)
+	if err != nil {
+		return []Client{}, Page{}, err
 	}
 
 	resp, err := newNetworkClient(cs.config).MakeRequest(network.Request{
 		Method:                "GET",
 		Path:                  requestPath.String(),
-		Authorization:         network.NewTokenAuthorization(token),
+		Authorization:         authorization,
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
-		return []Client{}, translateError(err)
+		return []Client{}, Page{}, translateError(err)
 	}
 
 	var document documents.ClientListResponse
 	err = json.Unmarshal(resp.Body, &document)
 	if err != nil {
-		return []Client{}, MalformedResponseError{err}
+		return []Client{}, Page{}, MalformedResponseError{err}
 	}
 
 	var list []Client
@@ -102,18 +149,46 @@ func (cs ClientsService) List(query Query, token string) ([]Client, error) {
 		list = append(list, newClientFromDocument(c))
 	}
 
-	return list, nil
+	return list, newPage(document.StartIndex, document.ItemsPerPage, document.TotalResults), nil
+}
+
+// Iterate returns a ClientsIterator that walks every page of client resources matching the given
+// query, making requests to UAA as each page is needed. Query.StartIndex and Query.Count, when set,
+// control where iteration begins and how many resources are fetched per request.
+func (cs ClientsService) Iterate(query Query, token string) *ClientsIterator {
+	return cs.IterateWithContext(context.Background(), query, token)
+}
+
+// IterateWithContext is like Iterate, but uses the given context for the requests to UAA.
+func (cs ClientsService) IterateWithContext(ctx context.Context, query Query, token string) *ClientsIterator {
+	return &ClientsIterator{
+		pager: newPager(ctx, query),
+		list: func(ctx context.Context, query Query) ([]Client, Page, error) {
+			return cs.ListPageWithContext(ctx, query, token)
+		},
+	}
 }
 
 // Update will make a request to UAA to update the matching client resource.
 // A token with the "clients.write" or "clients.admin" scope is required.
 func (cs ClientsService) Update(client Client, token string) error {
-	_, err := newNetworkClient(cs.config).MakeRequest(network.Request{
-		Method:        "PUT",
-		Path:          fmt.Sprintf("/oauth/clients/%s", client.ID),
-		Authorization: network.NewTokenAuthorization(token),
-		Body:          network.NewJSONRequestBody(client.toDocument("")),
+	return cs.UpdateWithContext(context.Background(), client, token)
+}
+
+// UpdateWithContext is like Update, but uses the given context for the request to UAA.
+func (cs ClientsService) UpdateWithContext(ctx context.Context, client Client, token string) error {
+	authorization, err := tokenAuthorization(ctx, cs.config, token)
+	if err != nil {
+		return err
+	}
+
+	_, err = newNetworkClient(cs.config).MakeRequest(network.Request{
+		Method:                "PUT",
+		Path:                  fmt.Sprintf("/oauth/clients/%s", client.ID),
+		Authorization:         authorization,
+		Body:                  network.NewJSONRequestBody(client.toDocument("")),
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return translateError(err)
@@ -125,11 +200,49 @@ func (cs ClientsService) Update(client Client, token string) error {
 // Delete will make a request to UAA to delete the client matching the given id.
 // A token with the "clients.write" or "clients.admin" scope is required.
 func (cs ClientsService) Delete(id, token string) error {
-	_, err := newNetworkClient(cs.config).MakeRequest(network.Request{
+	return cs.DeleteWithContext(context.Background(), id, token)
+}
+
+// DeleteWithContext is like Delete, but uses the given context for the request to UAA.
+func (cs ClientsService) DeleteWithContext(ctx context.Context, id, token string) error {
+	authorization, err := tokenAuthorization(ctx, cs.config, token)
+	if err != nil {
+		return err
+	}
+
+	_, err = newNetworkClient(cs.config).MakeRequest(network.Request{
 		Method:                "DELETE",
 		Path:                  fmt.Sprintf("/oauth/clients/%s", id),
-		Authorization:         network.NewTokenAuthorization(token),
+		Authorization:         authorization,
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
+	})
+	if err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// RevokeTokens will make a request to UAA to revoke all of the tokens granted to the client with the
+// matching id. A token with the "tokens.revoke" scope or the "uaa.admin" authority is required.
+func (cs ClientsService) RevokeTokens(id string, token string) error {
+	return cs.RevokeTokensWithContext(context.Background(), id, token)
+}
+
+// RevokeTokensWithContext is like RevokeTokens, but uses the given context for the request to UAA.
+func (cs ClientsService) RevokeTokensWithContext(ctx context.Context, id string, token string) error {
+	authorization, err := tokenAuthorization(ctx, cs.config, token)
+	if err != nil {
+		return err
+	}
+
+	_, err = newNetworkClient(cs.config).MakeRequest(network.Request{
+		Method:                "GET",
+		Path:                  fmt.Sprintf("/oauth/token/revoke/client/%s", id),
+		Authorization:         authorization,
+		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return translateError(err)
@@ -141,6 +254,28 @@ func (cs ClientsService) Delete(id, token string) error {
 // GetToken will make a request to UAA to retrieve a client token using the
 // "client_credentials" grant type. A client id and secret are required.
 func (cs ClientsService) GetToken(id, secret string) (string, error) {
+	return cs.GetTokenWithContext(context.Background(), id, secret)
+}
+
+// GetTokenWithContext is like GetToken, but uses the given context for the request to UAA.
+func (cs ClientsService) GetTokenWithContext(ctx context.Context, id, secret string) (string, error) {
+	response, err := cs.GetTokenResponseWithContext(ctx, id, secret)
+	if err != nil {
+		return "", err
+	}
+
+	return response.AccessToken, nil
+}
+
+// GetTokenResponse will make a request to UAA to retrieve the tokens for a client using the
+// "client_credentials" grant type. Unlike GetToken, the full TokenResponse is returned.
+// A client id and secret are required.
+func (cs ClientsService) GetTokenResponse(id, secret string) (TokenResponse, error) {
+	return cs.GetTokenResponseWithContext(context.Background(), id, secret)
+}
+
+// GetTokenResponseWithContext is like GetTokenResponse, but uses the given context for the request to UAA.
+func (cs ClientsService) GetTokenResponseWithContext(ctx context.Context, id, secret string) (TokenResponse, error) {
 	resp, err := newNetworkClient(cs.config).MakeRequest(network.Request{
 		Method:        "POST",
 		Path:          "/oauth/token",
@@ -150,16 +285,17 @@ func (cs ClientsService) GetToken(id, secret string) (string, error) {
 			"grant_type": []string{"client_credentials"},
 		}),
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
-		return "", translateError(err)
+		return TokenResponse{}, translateError(err)
 	}
 
 	var response documents.TokenResponse
 	err = json.Unmarshal(resp.Body, &response)
 	if err != nil {
-		return "", MalformedResponseError{err}
+		return TokenResponse{}, MalformedResponseError{err}
 	}
 
-	return response.AccessToken, nil
+	return newTokenResponseFromDocument(response), nil
 }
@@ -1,6 +1,7 @@
 package warrant_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -160,12 +161,20 @@ var _ = Describe("ClientsService", func() {
 
 			segments := strings.Split(clientToken, ".")
 			Expect(segments).To(HaveLen(3))
+			Expect(decodedToken.JTI).NotTo(BeEmpty())
+			Expect(decodedToken.IssuedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+			Expect(decodedToken.ExpiresAt).To(Equal(decodedToken.IssuedAt.Add(5000 * time.Second)))
 			Expect(decodedToken).To(Equal(warrant.Token{
-				Algorithm: "RS256",
-				KeyID:     "legacy-token-key",
-				ClientID:  client.ID,
-				Scopes:    []string{"openid", "bananas.eat"},
-				Issuer:    fmt.Sprintf("%s/oauth/token", fakeUAA.URL()),
+				Algorithm:   "RS256",
+				KeyID:       "legacy-token-key",
+				ClientID:    client.ID,
+				Scopes:      []string{"openid", "bananas.eat"},
+				Authorities: []string{"scim.read", "scim.write"},
+				Audiences:   []string{"none"},
+				Issuer:      fmt.Sprintf("%s/oauth/token", fakeUAA.URL()),
+				JTI:         decodedToken.JTI,
+				IssuedAt:    decodedToken.IssuedAt,
+				ExpiresAt:   decodedToken.ExpiresAt,
 				Segments: warrant.TokenSegments{
 					Header:    segments[0],
 					Claims:    segments[1],
@@ -192,6 +201,34 @@ var _ = Describe("ClientsService", func() {
 		})
 	})
 
+	Describe("GetTokenResponse", func() {
+		BeforeEach(func() {
+			err := service.Create(warrant.Client{
+				ID:                   "client-id",
+				Scope:                []string{"openid", "bananas.eat"},
+				ResourceIDs:          []string{"none"},
+				AuthorizedGrantTypes: []string{"client_credentials"},
+			}, "client-secret", token)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("retrieves the full token response for the client", func() {
+			response, err := service.GetTokenResponse("client-id", "client-secret")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.AccessToken).NotTo(BeEmpty())
+			Expect(response.RefreshToken).To(BeEmpty())
+			Expect(response.TokenType).To(Equal("bearer"))
+			Expect(response.Expiry).To(BeTemporally("~", time.Now().Add(5000*time.Second), time.Second))
+			Expect(response.Scopes).To(Equal([]string{"openid", "bananas.eat"}))
+			Expect(response.JTI).NotTo(BeEmpty())
+		})
+
+		It("returns an error when the client does not exist", func() {
+			_, err := service.GetTokenResponse("unknown-client", "client-secret")
+			Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+		})
+	})
+
 	Describe("Delete", func() {
 		var client warrant.Client
 
@@ -232,6 +269,43 @@ var _ = Describe("ClientsService", func() {
 		})
 	})
 
+	Describe("RevokeTokens", func() {
+		BeforeEach(func() {
+			err := service.Create(warrant.Client{
+				ID:                   "client-id",
+				ResourceIDs:          []string{"scim"},
+				Authorities:          []string{"scim.read"},
+				AuthorizedGrantTypes: []string{"client_credentials"},
+			}, "secret", token)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("revokes the tokens granted to the client", func() {
+			clientToken, err := service.GetToken("client-id", "secret")
+			Expect(err).NotTo(HaveOccurred())
+
+			err = service.RevokeTokens("client-id", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = warrant.NewUsersService(config).List(warrant.Query{}, clientToken)
+			Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+
+			newClientToken, err := service.GetToken("client-id", "secret")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = warrant.NewUsersService(config).List(warrant.Query{}, newClientToken)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = service.Get("client-id", token)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an error when the client does not exist", func() {
+			err := service.RevokeTokens("non-existent-client", token)
+			Expect(err).To(BeAssignableToTypeOf(warrant.NotFoundError{}))
+		})
+	})
+
 	Describe("List", func() {
 		var client warrant.Client
 		var otherClient warrant.Client
@@ -273,6 +347,17 @@ var _ = Describe("ClientsService", func() {
 			Expect(clients[0].ID).To(Equal("xyz-client"))
 		})
 
+		It("finds clients using compound filter expressions", func() {
+			clients, err := service.List(warrant.Query{
+				Filter: `name sw "other" or client_id eq "xyz-client"`,
+			}, token)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(clients).To(HaveLen(2))
+			Expect(clients[0].ID).To(Equal("abc-client"))
+			Expect(clients[1].ID).To(Equal("xyz-client"))
+		})
+
 		It("returns an empty list of clients if nothing matches the filter", func() {
 			clients, err := service.List(warrant.Query{
 				Filter: "id eq 'not-a-real-id'",
@@ -307,6 +392,44 @@ var _ = Describe("ClientsService", func() {
 			Expect(clients[2].Name).To(Equal("other-client"))
 		})
 
+		It("lists clients with a clients.admin token", func() {
+			adminClient := warrant.Client{
+				ID:          "clients-admin",
+				Authorities: []string{"clients.admin"},
+			}
+
+			err := service.Create(adminClient, "secret", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			adminToken, err := service.GetToken(adminClient.ID, "secret")
+			Expect(err).NotTo(HaveOccurred())
+
+			clients, err := service.List(warrant.Query{}, adminToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clients).To(HaveLen(4))
+		})
+
+		It("errors when the token is invalid", func() {
+			_, err := service.List(warrant.Query{}, "not-a-token")
+			Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+		})
+
+		It("errors when the token only has the clients.write scope", func() {
+			writerClient := warrant.Client{
+				ID:          "clients-writer",
+				Authorities: []string{"clients.write"},
+			}
+
+			err := service.Create(writerClient, "secret", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			writerToken, err := service.GetToken(writerClient.ID, "secret")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = service.List(warrant.Query{}, writerToken)
+			Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
+		})
+
 		It("errors when the token is unauthorized", func() {
 			unauthorizedClient := warrant.Client{ID: "unauthorized-client"}
 
@@ -318,7 +441,41 @@ var _ = Describe("ClientsService", func() {
 
 			_, err = service.List(warrant.Query{}, unauthorizedToken)
 			Expect(err).To(HaveOccurred())
-			Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+			Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
+		})
+	})
+
+	Describe("Iterate", func() {
+		It("walks every page of clients", func() {
+			for _, id := range []string{"client-a", "client-b", "client-c"} {
+				err := service.Create(warrant.Client{
+					ID:                   id,
+					AuthorizedGrantTypes: []string{"client_credentials"},
+				}, "secret", token)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			iterator := service.Iterate(warrant.Query{Count: 1}, token)
+
+			var ids []string
+			for iterator.Next() {
+				ids = append(ids, iterator.Client().ID)
+			}
+			Expect(iterator.Err()).NotTo(HaveOccurred())
+
+			Expect(ids).To(Equal([]string{"admin", "client-a", "client-b", "client-c"}))
+		})
+	})
+
+	Describe("GetTokenWithContext", func() {
+		It("returns an error when the context deadline has been exceeded", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+			defer cancel()
+			<-ctx.Done()
+
+			_, err := service.GetTokenWithContext(ctx, "admin", "admin")
+			Expect(err).To(BeAssignableToTypeOf(warrant.UnknownError{}))
+			Expect(err.Error()).To(ContainSubstring("context deadline exceeded"))
 		})
 	})
 })
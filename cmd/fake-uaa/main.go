@@ -0,0 +1,192 @@
+// Command fake-uaa runs the fake UAA server provided by the testserver
+// package as a standalone process, so that it can stand in for a real
+// UAA when developing services locally or in languages other than Go.
+//
+// Usage:
+//
+//	fake-uaa [flags]
+//
+// The flags are:
+//
+//	-address
+//		the address on which to listen (default ":8080")
+//	-url
+//		the url at which clients reach the server, used as the token
+//		issuer (defaults to one derived from -address)
+//	-tls-cert, -tls-key
+//		paths to a PEM encoded certificate and key with which to
+//		serve HTTPS
+//	-fixtures
+//		path to a YAML or JSON file of users, groups, and clients
+//		with which to seed the server
+//	-signing-key
+//		path to a PEM encoded RSA, ECDSA, or Ed25519 private key with
+//		which to sign tokens (defaults to a randomly generated key)
+//	-signing-algorithm
+//		the algorithm with which to sign tokens, such as "RS256",
+//		"PS256", "ES256", or "EdDSA" (defaults to one suited to the
+//		signing key)
+//	-key-id
+//		the id of the signing key, included in the "kid" header of
+//		tokens (default "legacy-token-key")
+//	-admin-client-id, -admin-client-secret
+//		the credentials of the admin client that the server always
+//		includes (default "admin" and "admin")
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pivotal-cf-experimental/warrant/testserver"
+)
+
+type flags struct {
+	address     string
+	url         string
+	tlsCert     string
+	tlsKey      string
+	fixtures    string
+	signingKey  string
+	algorithm   string
+	keyID       string
+	adminID     string
+	adminSecret string
+}
+
+func main() {
+	f, err := parseFlags(os.Args[1:])
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
+	if err := run(f); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parseFlags(args []string) (flags, error) {
+	var f flags
+	set := flag.NewFlagSet("fake-uaa", flag.ContinueOnError)
+	set.StringVar(&f.address, "address", ":8080", "the address on which to listen")
+	set.StringVar(&f.url, "url", "", "the url at which clients reach the server, used as the token issuer")
+	set.StringVar(&f.tlsCert, "tls-cert", "", "path to a PEM encoded certificate with which to serve HTTPS")
+	set.StringVar(&f.tlsKey, "tls-key", "", "path to the PEM encoded private key of the -tls-cert certificate")
+	set.StringVar(&f.fixtures, "fixtures", "", "path to a YAML or JSON file of fixtures with which to seed the server")
+	set.StringVar(&f.signingKey, "signing-key", "", "path to a PEM encoded RSA, ECDSA, or Ed25519 private key with which to sign tokens")
+	set.StringVar(&f.algorithm, "signing-algorithm", "", "the algorithm with which to sign tokens, such as RS256, PS256, ES256, or EdDSA")
+	set.StringVar(&f.keyID, "key-id", "", "the id of the signing key, included in the kid header of tokens")
+	set.StringVar(&f.adminID, "admin-client-id", "", "the id of the admin client")
+	set.StringVar(&f.adminSecret, "admin-client-secret", "", "the secret of the admin client")
+
+	if err := set.Parse(args); err != nil {
+		return flags{}, err
+	}
+
+	return f, nil
+}
+
+func run(f flags) error {
+	uaa, err := newUAA(f)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", f.address)
+	if err != nil {
+		return err
+	}
+
+	url := f.url
+	if url == "" {
+		url = defaultURL(listener.Addr(), f.tlsCert != "")
+	}
+	uaa.SetURL(url)
+
+	server := &http.Server{Handler: uaa}
+
+	errs := make(chan error, 1)
+	go func() {
+		if f.tlsCert != "" {
+			errs <- server.ServeTLS(listener, f.tlsCert, f.tlsKey)
+		} else {
+			errs <- server.Serve(listener)
+		}
+	}()
+
+	log.Printf("fake UAA listening on %s", url)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-signals:
+		return server.Shutdown(context.Background())
+	}
+}
+
+func newUAA(f flags) (*testserver.UAA, error) {
+	if (f.tlsCert == "") != (f.tlsKey == "") {
+		return nil, fmt.Errorf("-tls-cert and -tls-key must be given together")
+	}
+
+	config := testserver.Config{
+		SigningAlgorithm:  f.algorithm,
+		KeyID:             f.keyID,
+		AdminClientID:     f.adminID,
+		AdminClientSecret: f.adminSecret,
+	}
+
+	if f.signingKey != "" {
+		key, err := ioutil.ReadFile(f.signingKey)
+		if err != nil {
+			return nil, err
+		}
+
+		config.SigningKey = string(key)
+	}
+
+	uaa, err := testserver.NewUAAWithConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("could not configure signing key: %s", err)
+	}
+
+	if f.fixtures != "" {
+		if err := uaa.SeedFile(f.fixtures); err != nil {
+			return nil, err
+		}
+	}
+
+	return uaa, nil
+}
+
+func defaultURL(addr net.Addr, secure bool) string {
+	scheme := "http"
+	if secure {
+		scheme = "https"
+	}
+
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return fmt.Sprintf("%s://%s", scheme, addr)
+	}
+
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = "localhost"
+	}
+
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, port))
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pivotal-cf-experimental/warrant"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("fake-uaa", func() {
+	var dir string
+
+	writeFile := func(name string, contents []byte) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, contents, 0600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "fake-uaa")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Describe("parseFlags", func() {
+		It("parses the flags", func() {
+			f, err := parseFlags([]string{
+				"-address", "127.0.0.1:9090",
+				"-url", "https://uaa.example.com",
+				"-tls-cert", "cert.pem",
+				"-tls-key", "key.pem",
+				"-fixtures", "fixtures.yml",
+				"-signing-key", "signing-key.pem",
+				"-signing-algorithm", "ES256",
+				"-key-id", "some-key-id",
+				"-admin-client-id", "root",
+				"-admin-client-secret", "s3cr3t",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f).To(Equal(flags{
+				address:     "127.0.0.1:9090",
+				url:         "https://uaa.example.com",
+				tlsCert:     "cert.pem",
+				tlsKey:      "key.pem",
+				fixtures:    "fixtures.yml",
+				signingKey:  "signing-key.pem",
+				algorithm:   "ES256",
+				keyID:       "some-key-id",
+				adminID:     "root",
+				adminSecret: "s3cr3t",
+			}))
+		})
+
+		It("listens on port 8080 by default", func() {
+			f, err := parseFlags([]string{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f).To(Equal(flags{address: ":8080"}))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when a flag is not defined", func() {
+				_, err := parseFlags([]string{"-not-a-flag"})
+				Expect(err).To(MatchError("flag provided but not defined: -not-a-flag"))
+			})
+		})
+	})
+
+	Describe("newUAA", func() {
+		It("configures the server from the flags", func() {
+			privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+
+			der, err := x509.MarshalECPrivateKey(privateKey)
+			Expect(err).NotTo(HaveOccurred())
+
+			uaa, err := newUAA(flags{
+				signingKey: writeFile("signing-key.pem", pem.EncodeToMemory(&pem.Block{
+					Type:  "EC PRIVATE KEY",
+					Bytes: der,
+				})),
+				keyID:       "some-key-id",
+				adminID:     "root",
+				adminSecret: "s3cr3t",
+				fixtures: writeFile("fixtures.yml", []byte(`
+clients:
+- client_id: app
+  client_secret: secret
+  authorized_grant_types: [client_credentials]
+`)),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			uaa.Start()
+			defer uaa.Close()
+
+			w := warrant.New(warrant.Config{
+				Host:          uaa.URL(),
+				SkipVerifySSL: true,
+			})
+
+			_, err = w.Clients.GetToken("root", "s3cr3t")
+			Expect(err).NotTo(HaveOccurred())
+
+			token, err := w.Clients.GetToken("app", "secret")
+			Expect(err).NotTo(HaveOccurred())
+
+			decodedToken, err := w.Tokens.Decode(token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.Algorithm).To(Equal("ES256"))
+			Expect(decodedToken.KeyID).To(Equal("some-key-id"))
+
+			keys, err := w.Tokens.GetSigningKeys()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.Verify(keys)).To(Succeed())
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when only one of -tls-cert and -tls-key is given", func() {
+				_, err := newUAA(flags{tlsCert: "cert.pem"})
+				Expect(err).To(MatchError("-tls-cert and -tls-key must be given together"))
+			})
+
+			It("returns an error when the signing key cannot be read", func() {
+				_, err := newUAA(flags{signingKey: filepath.Join(dir, "missing.pem")})
+				Expect(err).To(BeAssignableToTypeOf(&os.PathError{}))
+			})
+
+			It("returns an error when the signing key cannot be parsed", func() {
+				_, err := newUAA(flags{signingKey: writeFile("signing-key.pem", []byte("not a key"))})
+				Expect(err).To(MatchError("could not configure signing key: failed to decode PEM block containing private key"))
+			})
+
+			It("returns an error when the fixtures cannot be read", func() {
+				_, err := newUAA(flags{fixtures: filepath.Join(dir, "missing.yml")})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
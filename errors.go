@@ -1,15 +1,42 @@
 package warrant
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/pivotal-cf-experimental/warrant/internal/documents"
 	"github.com/pivotal-cf-experimental/warrant/internal/network"
 )
 
+// ErrorResponse describes the error reported by UAA in the body of an
+// unsuccessful response. It is embedded in each of the errors returned when
+// UAA responds with an unexpected status code. Fields that are not present in
+// the response body are left empty.
+type ErrorResponse struct {
+	// Status is the HTTP status code of the response.
+	Status int
+
+	// Code is the OAuth or SCIM error code given in the "error" field of the
+	// response, such as "invalid_scope" or "scim_resource_already_exists".
+	Code string
+
+	// Description is the human readable description of the error, given in
+	// the "error_description" or "message" field of the response.
+	Description string
+
+	// SCIMStatus is the status code given in the body of a SCIM error
+	// response, which may differ from the status code of the response itself.
+	SCIMStatus int
+}
+
 // UnexpectedStatusError indicates that UAA returned a status code that was unexpected.
 // The error message should provide some information about the specific error.
 type UnexpectedStatusError struct {
+	ErrorResponse
 	err error
 }
 
@@ -18,10 +45,16 @@ func (e UnexpectedStatusError) Error() string {
 	return e.err.Error()
 }
 
+// Unwrap returns the cause of the UnexpectedStatusError.
+func (e UnexpectedStatusError) Unwrap() error {
+	return cause(e.ErrorResponse, e.err)
+}
+
 // UnauthorizedError indicates that the requested action was unauthorized.
 // This could mean that the provided token is invalid, or does not contain
 // the required scope.
 type UnauthorizedError struct {
+	ErrorResponse
 	err error
 }
 
@@ -30,10 +63,18 @@ func (e UnauthorizedError) Error() string {
 	return e.err.Error()
 }
 
+// Unwrap returns the cause of the UnauthorizedError. This is an
+// InvalidTokenError, InsufficientScopeError, InvalidClientError, or
+// InvalidGrantError when UAA reports the corresponding error code.
+func (e UnauthorizedError) Unwrap() error {
+	return cause(e.ErrorResponse, e.err)
+}
+
 // ForbiddenError indicates that the requested action was unauthorized.
 // This could mean that the provided token does not contain
 // the required scope.
 type ForbiddenError struct {
+	ErrorResponse
 	err error
 }
 
@@ -42,8 +83,14 @@ func (e ForbiddenError) Error() string {
 	return e.err.Error()
 }
 
+// Unwrap returns the cause of the ForbiddenError.
+func (e ForbiddenError) Unwrap() error {
+	return cause(e.ErrorResponse, e.err)
+}
+
 // NotFoundError indicates that the resource could not be found.
 type NotFoundError struct {
+	ErrorResponse
 	err error
 }
 
@@ -52,6 +99,11 @@ func (e NotFoundError) Error() string {
 	return e.err.Error()
 }
 
+// Unwrap returns the cause of the NotFoundError.
+func (e NotFoundError) Unwrap() error {
+	return cause(e.ErrorResponse, e.err)
+}
+
 // UnknownError indicates that an error of unknown type has been encountered.
 type UnknownError struct {
 	err error
@@ -62,9 +114,17 @@ func (e UnknownError) Error() string {
 	return e.err.Error()
 }
 
-// InvalidTokenError indicates that the provided token is invalid.
-// The specific issue can be found by viewing the Error() return value.
+// Unwrap returns the cause of the UnknownError.
+func (e UnknownError) Unwrap() error {
+	return e.err
+}
+
+// InvalidTokenError indicates that the provided token is invalid, either
+// because it cannot be decoded, or because UAA responded with the
+// "invalid_token" error code. The specific issue can be found by viewing the
+// Error() return value.
 type InvalidTokenError struct {
+	ErrorResponse
 	err error
 }
 
@@ -73,6 +133,64 @@ func (e InvalidTokenError) Error() string {
 	return e.err.Error()
 }
 
+// Unwrap returns the cause of the InvalidTokenError.
+func (e InvalidTokenError) Unwrap() error {
+	return e.err
+}
+
+// InsufficientScopeError indicates that UAA responded with the
+// "insufficient_scope" error code, as the provided token does not contain
+// the scope required by the requested action.
+type InsufficientScopeError struct {
+	ErrorResponse
+	err error
+}
+
+// Error returns a string representation of the InsufficientScopeError.
+func (e InsufficientScopeError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the cause of the InsufficientScopeError.
+func (e InsufficientScopeError) Unwrap() error {
+	return e.err
+}
+
+// InvalidClientError indicates that UAA responded with the "invalid_client"
+// error code, as the client could not be authenticated.
+type InvalidClientError struct {
+	ErrorResponse
+	err error
+}
+
+// Error returns a string representation of the InvalidClientError.
+func (e InvalidClientError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the cause of the InvalidClientError.
+func (e InvalidClientError) Unwrap() error {
+	return e.err
+}
+
+// InvalidGrantError indicates that UAA responded with the "invalid_grant"
+// error code, as the provided credentials, authorization code, or refresh
+// token are invalid.
+type InvalidGrantError struct {
+	ErrorResponse
+	err error
+}
+
+// Error returns a string representation of the InvalidGrantError.
+func (e InvalidGrantError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the cause of the InvalidGrantError.
+func (e InvalidGrantError) Unwrap() error {
+	return e.err
+}
+
 // MalformedResponseError indicates that the response received from UAA is malformed.
 type MalformedResponseError struct {
 	err error
@@ -83,9 +201,15 @@ func (e MalformedResponseError) Error() string {
 	return fmt.Sprintf("malformed response: %s", e.err)
 }
 
+// Unwrap returns the cause of the MalformedResponseError.
+func (e MalformedResponseError) Unwrap() error {
+	return e.err
+}
+
 // BadRequestError indicates that the request sent to UAA is invalid.
 // The specific issue can be found by inspecting the Error() output.
 type BadRequestError struct {
+	ErrorResponse
 	err error
 }
 
@@ -94,9 +218,15 @@ func (e BadRequestError) Error() string {
 	return fmt.Sprintf("bad request: %s", e.err.(network.UnexpectedStatusError).Body)
 }
 
+// Unwrap returns the cause of the BadRequestError.
+func (e BadRequestError) Unwrap() error {
+	return cause(e.ErrorResponse, e.err)
+}
+
 // DuplicateResourceError indicates that the action committed against the resource
 // would result in a duplicate.
 type DuplicateResourceError struct {
+	ErrorResponse
 	err error
 }
 
@@ -105,24 +235,161 @@ func (e DuplicateResourceError) Error() string {
 	return fmt.Sprintf("duplicate resource: %s", e.err.(network.UnexpectedStatusError).Body)
 }
 
+// Unwrap returns the cause of the DuplicateResourceError.
+func (e DuplicateResourceError) Unwrap() error {
+	return cause(e.ErrorResponse, e.err)
+}
+
+// TokenExpiredError indicates that the token has expired.
+type TokenExpiredError struct {
+	// ExpiresAt is the time at which the token expired.
+	ExpiresAt time.Time
+}
+
+// Error returns a string representation of the TokenExpiredError.
+func (e TokenExpiredError) Error() string {
+	return fmt.Sprintf("token expired at %s", e.ExpiresAt.Format(time.RFC3339))
+}
+
+// TokenNotYetValidError indicates that the token is being used before the
+// time given in its "nbf" claim.
+type TokenNotYetValidError struct {
+	// NotBefore is the time at which the token becomes valid.
+	NotBefore time.Time
+}
+
+// Error returns a string representation of the TokenNotYetValidError.
+func (e TokenNotYetValidError) Error() string {
+	return fmt.Sprintf("token is not valid before %s", e.NotBefore.Format(time.RFC3339))
+}
+
+// TokenIssuedInFutureError indicates that the time given in the "iat" claim
+// of the token is in the future.
+type TokenIssuedInFutureError struct {
+	// IssuedAt is the time at which the token claims to have been issued.
+	IssuedAt time.Time
+}
+
+// Error returns a string representation of the TokenIssuedInFutureError.
+func (e TokenIssuedInFutureError) Error() string {
+	return fmt.Sprintf("token was issued in the future at %s", e.IssuedAt.Format(time.RFC3339))
+}
+
+// TokenIssuerError indicates that the token was not generated by the expected issuer.
+type TokenIssuerError struct {
+	// Issuer is the value of the "iss" claim of the token.
+	Issuer string
+
+	// Expected is the issuer that was required.
+	Expected string
+}
+
+// Error returns a string representation of the TokenIssuerError.
+func (e TokenIssuerError) Error() string {
+	return fmt.Sprintf("token issuer %q does not match expected issuer %q", e.Issuer, e.Expected)
+}
+
+// TokenAudienceError indicates that the token is not intended for a required audience.
+type TokenAudienceError struct {
+	// Audience is the required audience missing from the "aud" claim of the token.
+	Audience string
+}
+
+// Error returns a string representation of the TokenAudienceError.
+func (e TokenAudienceError) Error() string {
+	return fmt.Sprintf("token is missing required audience %q", e.Audience)
+}
+
+// TokenScopeError indicates that the token has not been granted a required scope.
+type TokenScopeError struct {
+	// Scope is the required scope missing from the "scope" claim of the token.
+	Scope string
+}
+
+// Error returns a string representation of the TokenScopeError.
+func (e TokenScopeError) Error() string {
+	return fmt.Sprintf("token is missing required scope %q", e.Scope)
+}
+
 func translateError(err error) error {
+	response := newErrorResponse(err)
+
 	switch s := err.(type) {
 	case network.NotFoundError:
-		return NotFoundError{err}
+		return NotFoundError{response, err}
 	case network.ForbiddenError:
-		return ForbiddenError{err}
+		return ForbiddenError{response, err}
 	case network.UnauthorizedError:
-		return UnauthorizedError{err}
+		return UnauthorizedError{response, err}
 	case network.UnexpectedStatusError:
 		switch s.Status {
 		case http.StatusBadRequest:
-			return BadRequestError{err}
+			return BadRequestError{response, err}
 		case http.StatusConflict:
-			return DuplicateResourceError{err}
+			return DuplicateResourceError{response, err}
 		default:
-			return UnexpectedStatusError{err}
+			return UnexpectedStatusError{response, err}
 		}
 	default:
 		return UnknownError{err}
 	}
 }
+
+// newErrorResponse parses the body of the response that caused the given
+// network error. UAA describes OAuth errors using the "error" and
+// "error_description" fields, while SCIM services may instead use the
+// formats described by SCIM 1.1 or 2.0.
+func newErrorResponse(err error) ErrorResponse {
+	r, ok := err.(interface{ Response() network.Response })
+	if !ok {
+		return ErrorResponse{}
+	}
+
+	resp := r.Response()
+	response := ErrorResponse{Status: resp.Code}
+
+	var document documents.ErrorResponse
+	if err := json.Unmarshal(resp.Body, &document); err != nil {
+		return response
+	}
+
+	response.Code = document.Error
+	response.SCIMStatus, _ = strconv.Atoi(strings.Trim(string(document.Status), `"`))
+
+	for _, description := range []string{document.ErrorDescription, document.Message, document.Detail} {
+		if description != "" {
+			response.Description = description
+			break
+		}
+	}
+
+	if len(document.Errors) > 0 {
+		if response.Description == "" {
+			response.Description = document.Errors[0].Description
+		}
+
+		if response.SCIMStatus == 0 {
+			response.SCIMStatus, _ = strconv.Atoi(document.Errors[0].Code)
+		}
+	}
+
+	return response
+}
+
+// cause returns the error that caused a response with the given error
+// response, which is one of the errors for the OAuth error codes when UAA
+// reported one.
+func cause(response ErrorResponse, err error) error {
+	switch response.Code {
+	case "invalid_token":
+		return InvalidTokenError{response, err}
+	case "insufficient_scope":
+		return InsufficientScopeError{response, err}
+	case "invalid_client":
+		return InvalidClientError{response, err}
+	case "invalid_grant":
+		return InvalidGrantError{response, err}
+	default:
+		return err
+	}
+}
@@ -0,0 +1,156 @@
+package warrant_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/pivotal-cf-experimental/warrant"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Errors", func() {
+	var (
+		client warrant.Warrant
+		status int
+		body   string
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		client = warrant.New(warrant.Config{
+			Host:          fakeUAA.URL(),
+			SkipVerifySSL: true,
+			TraceWriter:   TraceWriter,
+		})
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	requestWithResponse := func(responseStatus int, responseBody string) error {
+		status, body = responseStatus, responseBody
+
+		_, err := warrant.NewUsersService(warrant.Config{
+			Host:        server.URL,
+			TraceWriter: TraceWriter,
+		}).Get("some-user-id", "some-token")
+
+		return err
+	}
+
+	It("parses the OAuth error returned by UAA", func() {
+		_, err := client.Clients.GetToken("missing-client", "some-secret")
+
+		var unauthorizedErr warrant.UnauthorizedError
+		Expect(errors.As(err, &unauthorizedErr)).To(BeTrue())
+		Expect(unauthorizedErr.ErrorResponse).To(Equal(warrant.ErrorResponse{
+			Status:      http.StatusUnauthorized,
+			Code:        "invalid_client",
+			Description: "No client with requested id: missing-client",
+		}))
+
+		var invalidClientErr warrant.InvalidClientError
+		Expect(errors.As(err, &invalidClientErr)).To(BeTrue())
+		Expect(invalidClientErr.Code).To(Equal("invalid_client"))
+		Expect(invalidClientErr).To(MatchError(err.Error()))
+	})
+
+	It("parses the SCIM error returned by UAA", func() {
+		token, err := client.Clients.GetToken("admin", "admin")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.Users.Create("duplicate-user", "duplicate-user@example.com", token)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.Users.Create("duplicate-user", "duplicate-user@example.com", token)
+		Expect(err).To(BeAssignableToTypeOf(warrant.DuplicateResourceError{}))
+
+		duplicateErr := err.(warrant.DuplicateResourceError)
+		Expect(duplicateErr.Status).To(Equal(http.StatusConflict))
+		Expect(duplicateErr.Code).To(Equal("scim_resource_already_exists"))
+		Expect(duplicateErr.Description).To(Equal("Username already in use: duplicate-user"))
+	})
+
+	It("distinguishes the invalid_token error code", func() {
+		err := requestWithResponse(http.StatusUnauthorized, `{"error":"invalid_token","error_description":"Invalid access token"}`)
+		Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+
+		var invalidTokenErr warrant.InvalidTokenError
+		Expect(errors.As(err, &invalidTokenErr)).To(BeTrue())
+		Expect(invalidTokenErr.Description).To(Equal("Invalid access token"))
+	})
+
+	It("distinguishes the insufficient_scope error code", func() {
+		err := requestWithResponse(http.StatusForbidden, `{"error":"insufficient_scope","error_description":"Insufficient scope for this resource"}`)
+		Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
+
+		var insufficientScopeErr warrant.InsufficientScopeError
+		Expect(errors.As(err, &insufficientScopeErr)).To(BeTrue())
+		Expect(insufficientScopeErr.Status).To(Equal(http.StatusForbidden))
+		Expect(insufficientScopeErr.Description).To(Equal("Insufficient scope for this resource"))
+	})
+
+	It("distinguishes the invalid_grant error code", func() {
+		err := requestWithResponse(http.StatusBadRequest, `{"error":"invalid_grant","error_description":"Bad credentials"}`)
+		Expect(err).To(BeAssignableToTypeOf(warrant.BadRequestError{}))
+
+		var invalidGrantErr warrant.InvalidGrantError
+		Expect(errors.As(err, &invalidGrantErr)).To(BeTrue())
+		Expect(invalidGrantErr.Description).To(Equal("Bad credentials"))
+
+		Expect(errors.As(err, &warrant.InvalidClientError{})).To(BeFalse())
+	})
+
+	It("parses the description from the message field", func() {
+		err := requestWithResponse(http.StatusNotFound, `{"error":"scim_resource_not_found","message":"User some-user-id does not exist"}`)
+		Expect(err).To(BeAssignableToTypeOf(warrant.NotFoundError{}))
+		Expect(err.(warrant.NotFoundError).Description).To(Equal("User some-user-id does not exist"))
+	})
+
+	It("parses SCIM 2.0 errors", func() {
+		err := requestWithResponse(http.StatusNotFound, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:Error"],"status":"404","detail":"Resource some-user-id not found"}`)
+		Expect(err).To(BeAssignableToTypeOf(warrant.NotFoundError{}))
+		Expect(err.(warrant.NotFoundError).ErrorResponse).To(Equal(warrant.ErrorResponse{
+			Status:      http.StatusNotFound,
+			Description: "Resource some-user-id not found",
+			SCIMStatus:  http.StatusNotFound,
+		}))
+	})
+
+	It("parses SCIM 1.1 errors", func() {
+		err := requestWithResponse(http.StatusConflict, `{"Errors":[{"description":"Resource some-user-id already exists","code":"409"}]}`)
+		Expect(err).To(BeAssignableToTypeOf(warrant.DuplicateResourceError{}))
+		Expect(err.(warrant.DuplicateResourceError).ErrorResponse).To(Equal(warrant.ErrorResponse{
+			Status:      http.StatusConflict,
+			Description: "Resource some-user-id already exists",
+			SCIMStatus:  http.StatusConflict,
+		}))
+	})
+
+	It("includes only the status when the response body is not JSON", func() {
+		err := requestWithResponse(http.StatusBadGateway, "502 Bad Gateway")
+		Expect(err).To(BeAssignableToTypeOf(warrant.UnexpectedStatusError{}))
+		Expect(err.(warrant.UnexpectedStatusError).ErrorResponse).To(Equal(warrant.ErrorResponse{
+			Status: http.StatusBadGateway,
+		}))
+	})
+
+	It("wraps the errors that caused a request to fail", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := client.Clients.GetTokenWithContext(ctx, "admin", "admin")
+		Expect(err).To(BeAssignableToTypeOf(warrant.UnknownError{}))
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+	})
+})
@@ -0,0 +1,209 @@
+package warrant
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	filterPrecedenceOr = iota + 1
+	filterPrecedenceAnd
+	filterPrecedenceAttribute
+)
+
+// Filter is a SCIM filtering expression that can be given as the Where field
+// of a Query. Filters are built using functions like Eq, And and Not, which
+// take care of quoting and escaping values and of grouping nested logical
+// expressions:
+//
+//	warrant.Query{
+//		Where: warrant.And(
+//			warrant.Eq("origin", "uaa"),
+//			warrant.Or(
+//				warrant.Sw("userName", "admin"),
+//				warrant.Co("emails.value", `"quoted"@example.com`),
+//			),
+//		),
+//	}
+//
+// renders as:
+//
+//	origin eq "uaa" and (userName sw "admin" or emails.value co "\"quoted\"@example.com")
+//
+// The zero value is an empty filter that matches every resource.
+type Filter struct {
+	expression string
+	precedence int
+}
+
+// RawFilter returns a Filter for the given filtering expression. The
+// expression is used as-is, so any values within it must already be escaped.
+func RawFilter(expression string) Filter {
+	if strings.TrimSpace(expression) == "" {
+		return Filter{}
+	}
+
+	return Filter{
+		expression: expression,
+		precedence: filterPrecedenceOr,
+	}
+}
+
+// Eq returns a Filter matching resources whose attribute is equal to the value.
+func Eq(attribute string, value interface{}) Filter {
+	return comparisonFilter(attribute, "eq", value)
+}
+
+// Ne returns a Filter matching resources whose attribute is not equal to the value.
+func Ne(attribute string, value interface{}) Filter {
+	return comparisonFilter(attribute, "ne", value)
+}
+
+// Co returns a Filter matching resources whose attribute contains the value.
+func Co(attribute string, value interface{}) Filter {
+	return comparisonFilter(attribute, "co", value)
+}
+
+// Sw returns a Filter matching resources whose attribute starts with the value.
+func Sw(attribute string, value interface{}) Filter {
+	return comparisonFilter(attribute, "sw", value)
+}
+
+// Ew returns a Filter matching resources whose attribute ends with the value.
+func Ew(attribute string, value interface{}) Filter {
+	return comparisonFilter(attribute, "ew", value)
+}
+
+// Gt returns a Filter matching resources whose attribute is greater than the value.
+func Gt(attribute string, value interface{}) Filter {
+	return comparisonFilter(attribute, "gt", value)
+}
+
+// Ge returns a Filter matching resources whose attribute is greater than or
+// equal to the value.
+func Ge(attribute string, value interface{}) Filter {
+	return comparisonFilter(attribute, "ge", value)
+}
+
+// Lt returns a Filter matching resources whose attribute is less than the value.
+func Lt(attribute string, value interface{}) Filter {
+	return comparisonFilter(attribute, "lt", value)
+}
+
+// Le returns a Filter matching resources whose attribute is less than or
+// equal to the value.
+func Le(attribute string, value interface{}) Filter {
+	return comparisonFilter(attribute, "le", value)
+}
+
+// Pr returns a Filter matching resources that have a non-empty value for the attribute.
+func Pr(attribute string) Filter {
+	return Filter{
+		expression: attribute + " pr",
+		precedence: filterPrecedenceAttribute,
+	}
+}
+
+// And returns a Filter matching resources that match all of the given
+// filters. Empty filters are ignored.
+func And(filters ...Filter) Filter {
+	return logicalFilter("and", filterPrecedenceAnd, filters)
+}
+
+// Or returns a Filter matching resources that match any of the given
+// filters. Empty filters are ignored.
+func Or(filters ...Filter) Filter {
+	return logicalFilter("or", filterPrecedenceOr, filters)
+}
+
+// Not returns a Filter matching resources that do not match the given filter.
+func Not(filter Filter) Filter {
+	if filter.IsEmpty() {
+		return Filter{}
+	}
+
+	return Filter{
+		expression: "not (" + filter.expression + ")",
+		precedence: filterPrecedenceAttribute,
+	}
+}
+
+// IsEmpty reports whether the filter is empty and so matches every resource.
+func (f Filter) IsEmpty() bool {
+	return f.expression == ""
+}
+
+// String returns the SCIM representation of the filter.
+func (f Filter) String() string {
+	return f.expression
+}
+
+func comparisonFilter(attribute, operator string, value interface{}) Filter {
+	return Filter{
+		expression: fmt.Sprintf("%s %s %s", attribute, operator, filterValue(value)),
+		precedence: filterPrecedenceAttribute,
+	}
+}
+
+func logicalFilter(operator string, precedence int, filters []Filter) Filter {
+	var operands []Filter
+	for _, filter := range filters {
+		if !filter.IsEmpty() {
+			operands = append(operands, filter)
+		}
+	}
+
+	switch len(operands) {
+	case 0:
+		return Filter{}
+	case 1:
+		return operands[0]
+	}
+
+	var expressions []string
+	for _, operand := range operands {
+		expression := operand.expression
+		if operand.precedence < precedence {
+			expression = "(" + expression + ")"
+		}
+
+		expressions = append(expressions, expression)
+	}
+
+	return Filter{
+		expression: strings.Join(expressions, " "+operator+" "),
+		precedence: precedence,
+	}
+}
+
+func filterValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return quoteFilterValue(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return quoteFilterValue(v.UTC().Format(time.RFC3339Nano))
+	case fmt.Stringer:
+		return quoteFilterValue(v.String())
+	default:
+		return quoteFilterValue(fmt.Sprint(v))
+	}
+}
+
+func quoteFilterValue(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+
+	return `"` + value + `"`
+}
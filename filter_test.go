@@ -0,0 +1,62 @@
+package warrant_test
+
+import (
+	"time"
+
+	"github.com/pivotal-cf-experimental/warrant"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Filter", func() {
+	It("renders attribute expressions", func() {
+		Expect(warrant.Eq("userName", "alice").String()).To(Equal(`userName eq "alice"`))
+		Expect(warrant.Co("emails.value", "example").String()).To(Equal(`emails.value co "example"`))
+		Expect(warrant.Sw("displayName", "scim.").String()).To(Equal(`displayName sw "scim."`))
+		Expect(warrant.Pr("externalId").String()).To(Equal(`externalId pr`))
+		Expect(warrant.Gt("meta.version", 2).String()).To(Equal(`meta.version gt 2`))
+	})
+
+	It("renders values according to their type", func() {
+		Expect(warrant.Eq("active", true).String()).To(Equal(`active eq true`))
+		Expect(warrant.Eq("externalId", nil).String()).To(Equal(`externalId eq null`))
+		Expect(warrant.Ge("score", 1.5).String()).To(Equal(`score ge 1.5`))
+
+		created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("EST", -5*60*60))
+		Expect(warrant.Gt("meta.created", created).String()).To(Equal(`meta.created gt "2020-01-02T08:04:05Z"`))
+	})
+
+	It("escapes quotes and backslashes in values", func() {
+		filter := warrant.Eq("userName", `o"brien\admin`)
+		Expect(filter.String()).To(Equal(`userName eq "o\"brien\\admin"`))
+	})
+
+	It("groups nested logical expressions", func() {
+		filter := warrant.And(
+			warrant.Eq("origin", "uaa"),
+			warrant.Or(
+				warrant.Sw("userName", "admin"),
+				warrant.Co("emails.value", "admin"),
+			),
+		)
+		Expect(filter.String()).To(Equal(`origin eq "uaa" and (userName sw "admin" or emails.value co "admin")`))
+
+		filter = warrant.Or(
+			warrant.And(warrant.Eq("origin", "uaa"), warrant.Pr("emails")),
+			warrant.Not(warrant.Eq("active", true)),
+		)
+		Expect(filter.String()).To(Equal(`origin eq "uaa" and emails pr or not (active eq true)`))
+	})
+
+	It("ignores empty filters", func() {
+		Expect(warrant.And().IsEmpty()).To(BeTrue())
+		Expect(warrant.Not(warrant.Filter{}).IsEmpty()).To(BeTrue())
+		Expect(warrant.Or(warrant.Filter{}, warrant.Eq("id", "1")).String()).To(Equal(`id eq "1"`))
+	})
+
+	It("groups raw filter expressions when they are combined", func() {
+		filter := warrant.And(warrant.RawFilter(`id eq "1" or id eq "2"`), warrant.Pr("emails"))
+		Expect(filter.String()).To(Equal(`(id eq "1" or id eq "2") and emails pr`))
+	})
+})
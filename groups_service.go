@@ -1,6 +1,7 @@
 package warrant
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,8 +12,6 @@ import (
 	"github.com/pivotal-cf-experimental/warrant/internal/network"
 )
 
-// TODO: Pagination for List
-
 // GroupsService provides access to common group actions. Using this service,
 // you can create, delete, fetch and list group resources.
 type GroupsService struct {
@@ -29,14 +28,25 @@ func NewGroupsService(config Config) GroupsService {
 // Create will make a request to UAA to create a new group resource with the given
 // DisplayName. A token with the "scim.write" scope is required.
 func (gs GroupsService) Create(displayName, token string) (Group, error) {
+	return gs.CreateWithContext(context.Background(), displayName, token)
+}
+
+// CreateWithContext is like Create, but uses the given context for the request to UAA.
+func (gs GroupsService) CreateWithContext(ctx context.Context, displayName, token string) (Group, error) {
+	authorization, err := tokenAuthorization(ctx, gs.config, token)
+	if err != nil {
+		return Group{}, err
+	}
+
 	resp, err := newNetworkClient(gs.config).MakeRequest(network.Request{
 		Method:        "POST",
 		Path:          "/Groups",
-		Authorization: network.NewTokenAuthorization(token),
+		Authorization: authorization,
 		Body: network.NewJSONRequestBody(documents.CreateGroupRequest{
 			DisplayName: displayName,
 		}),
 		AcceptableStatusCodes: []int{http.StatusCreated},
+		Context:               ctx,
 	})
 	if err != nil {
 		return Group{}, translateError(err)
@@ -54,13 +64,24 @@ func (gs GroupsService) Create(displayName, token string) (Group, error) {
 // Update will make a request to UAA to update the matching group resource.
 // A token with the "scim.write" or "groups.update" scope is required.
 func (gs GroupsService) Update(group Group, token string) (Group, error) {
+	return gs.UpdateWithContext(context.Background(), group, token)
+}
+
+// UpdateWithContext is like Update, but uses the given context for the request to UAA.
+func (gs GroupsService) UpdateWithContext(ctx context.Context, group Group, token string) (Group, error) {
+	authorization, err := tokenAuthorization(ctx, gs.config, token)
+	if err != nil {
+		return Group{}, err
+	}
+
 	resp, err := newNetworkClient(gs.config).MakeRequest(network.Request{
-		Method:        "PUT",
-		Path:          fmt.Sprintf("/Groups/%s", group.ID),
-		Authorization: network.NewTokenAuthorization(token),
-		IfMatch:       strconv.Itoa(group.Version),
-		Body:          network.NewJSONRequestBody(newUpdateGroupDocumentFromGroup(group)),
+		Method:                "PUT",
+		Path:                  fmt.Sprintf("/Groups/%s", group.ID),
+		Authorization:         authorization,
+		IfMatch:               strconv.Itoa(group.Version),
+		Body:                  network.NewJSONRequestBody(newUpdateGroupDocumentFromGroup(group)),
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return Group{}, translateError(err)
@@ -78,16 +99,27 @@ func (gs GroupsService) Update(group Group, token string) (Group, error) {
 // AddMember will make a request to UAA to add a member to the group resource with the matching id.
 // A token with the "scim.write" scope is required.
 func (gs GroupsService) AddMember(groupID, memberID, token string) (Member, error) {
+	return gs.AddMemberWithContext(context.Background(), groupID, memberID, token)
+}
+
+// AddMemberWithContext is like AddMember, but uses the given context for the request to UAA.
+func (gs GroupsService) AddMemberWithContext(ctx context.Context, groupID, memberID, token string) (Member, error) {
+	authorization, err := tokenAuthorization(ctx, gs.config, token)
+	if err != nil {
+		return Member{}, err
+	}
+
 	resp, err := newNetworkClient(gs.config).MakeRequest(network.Request{
 		Method:        "POST",
 		Path:          fmt.Sprintf("/Groups/%s/members", groupID),
-		Authorization: network.NewTokenAuthorization(token),
+		Authorization: authorization,
 		Body: network.NewJSONRequestBody(documents.CreateMemberRequest{
 			Origin: "uaa",
 			Type:   "USER",
 			Value:  memberID,
 		}),
 		AcceptableStatusCodes: []int{http.StatusCreated},
+		Context:               ctx,
 	})
 	if err != nil {
 		return Member{}, translateError(err)
@@ -105,11 +137,22 @@ func (gs GroupsService) AddMember(groupID, memberID, token string) (Member, erro
 // CheckMembership will make a request to UAA to fetch a member resource from a group resource.
 // A token with the "scim.read" scope is required.
 func (gs GroupsService) CheckMembership(groupID, memberID, token string) (Member, bool, error) {
+	return gs.CheckMembershipWithContext(context.Background(), groupID, memberID, token)
+}
+
+// CheckMembershipWithContext is like CheckMembership, but uses the given context for the request to UAA.
+func (gs GroupsService) CheckMembershipWithContext(ctx context.Context, groupID, memberID, token string) (Member, bool, error) {
+	authorization, err := tokenAuthorization(ctx, gs.config, token)
+	if err != nil {
+		return Member{}, false, err
+	}
+
 	resp, err := newNetworkClient(gs.config).MakeRequest(network.Request{
 		Method:                "GET",
 		Path:                  fmt.Sprintf("/Groups/%s/members/%s", groupID, memberID),
-		Authorization:         network.NewTokenAuthorization(token),
+		Authorization:         authorization,
 		AcceptableStatusCodes: []int{http.StatusOK, http.StatusNotFound},
+		Context:               ctx,
 	})
 	if err != nil {
 		return Member{}, false, translateError(err)
@@ -131,11 +174,22 @@ func (gs GroupsService) CheckMembership(groupID, memberID, token string) (Member
 // ListMembers will make a request to UAA to fetch the members of a group resource with the matching id.
 // A token with the "scim.read" scope is required.
 func (gs GroupsService) ListMembers(groupID, token string) ([]Member, error) {
+	return gs.ListMembersWithContext(context.Background(), groupID, token)
+}
+
+// ListMembersWithContext is like ListMembers, but uses the given context for the request to UAA.
+func (gs GroupsService) ListMembersWithContext(ctx context.Context, groupID, token string) ([]Member, error) {
+	authorization, err := tokenAuthorization(ctx, gs.config, token)
+	if err != nil {
+		return []Member{}, err
+	}
+
 	resp, err := newNetworkClient(gs.config).MakeRequest(network.Request{
 		Method:                "GET",
 		Path:                  fmt.Sprintf("/Groups/%s/members", groupID),
-		Authorization:         network.NewTokenAuthorization(token),
+		Authorization:         authorization,
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return []Member{}, translateError(err)
@@ -158,11 +212,22 @@ func (gs GroupsService) ListMembers(groupID, token string) ([]Member, error) {
 // RemoveMember will make a request to UAA to remove a member from a group resource.
 // A token with the "scim.write" scope is required.
 func (gs GroupsService) RemoveMember(groupID, memberID, token string) error {
-	_, err := newNetworkClient(gs.config).MakeRequest(network.Request{
+	return gs.RemoveMemberWithContext(context.Background(), groupID, memberID, token)
+}
+
+// RemoveMemberWithContext is like RemoveMember, but uses the given context for the request to UAA.
+func (gs GroupsService) RemoveMemberWithContext(ctx context.Context, groupID, memberID, token string) error {
+	authorization, err := tokenAuthorization(ctx, gs.config, token)
+	if err != nil {
+		return err
+	}
+
+	_, err = newNetworkClient(gs.config).MakeRequest(network.Request{
 		Method:                "DELETE",
 		Path:                  fmt.Sprintf("/Groups/%s/members/%s", groupID, memberID),
-		Authorization:         network.NewTokenAuthorization(token),
+		Authorization:         authorization,
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return translateError(err)
@@ -174,11 +239,22 @@ func (gs GroupsService) RemoveMember(groupID, memberID, token string) error {
 // Get will make a request to UAA to fetch the group resource with the matching id.
 // A token with the "scim.read" scope is required.
 func (gs GroupsService) Get(id, token string) (Group, error) {
+	return gs.GetWithContext(context.Background(), id, token)
+}
+
+// GetWithContext is like Get, but uses the given context for the request to UAA.
+func (gs GroupsService) GetWithContext(ctx context.Context, id, token string) (Group, error) {
+	authorization, err := tokenAuthorization(ctx, gs.config, token)
+	if err != nil {
+		return Group{}, err
+	}
+
 	resp, err := newNetworkClient(gs.config).MakeRequest(network.Request{
 		Method:                "GET",
 		Path:                  fmt.Sprintf("/Groups/%s", id),
-		Authorization:         network.NewTokenAuthorization(token),
+		Authorization:         authorization,
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return Group{}, translateError(err)
@@ -196,28 +272,53 @@ func (gs GroupsService) Get(id, token string) (Group, error) {
 // List wil make a request to UAA to list the groups that match the given Query.
 // A token with the "scim.read" scope is required.
 func (gs GroupsService) List(query Query, token string) ([]Group, error) {
+	return gs.ListWithContext(context.Background(), query, token)
+}
+
+// ListWithContext is like List, but uses the given context for the request to UAA.
+func (gs GroupsService) ListWithContext(ctx context.Context, query Query, token string) ([]Group, error) {
+	groupList, _, err := gs.ListPageWithContext(ctx, query, token)
+	if err != nil {
+		return []Group{}, err
+	}
+
+	return groupList, nil
+}
+
+// ListPage will make a request to UAA to retrieve a single page of group resources matching the
+// given query. The returned Page describes where that page sits within the full set of results.
+// Use Iterate to walk every page of results.
+func (gs GroupsService) ListPage(query Query, token string) ([]Group, Page, error) {
+	return gs.ListPageWithContext(context.Background(), query, token)
+}
+
+// ListPageWithContext is like ListPage, but uses the given context for the request to UAA.
+func (gs GroupsService) ListPageWithContext(ctx context.Context, query Query, token string) ([]Group, Page, error) {
 	requestPath := url.URL{
-		Path: "/Groups",
-		RawQuery: url.Values{
-			"filter": []string{query.Filter},
-			"sortBy": []string{query.SortBy},
-		}.Encode(),
+		Path:     "/Groups",
+		RawQuery: query.toValues().Encode(),
+	}
+
+	authorization, err := tokenAuthorization(ctx, gs.config, token)
+	if err != nil {
+		return []Group{}, Page{}, err
 	}
 
 	resp, err := newNetworkClient(gs.config).MakeRequest(network.Request{
 		Method:                "GET",
 		Path:                  requestPath.String(),
-		Authorization:         network.NewTokenAuthorization(token),
+		Authorization:         authorization,
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
-		return []Group{}, translateError(err)
+		return []Group{}, Page{}, translateError(err)
 	}
 
 	var response documents.GroupListResponse
 	err = json.Unmarshal(resp.Body, &response)
 	if err != nil {
-		return []Group{}, MalformedResponseError{err}
+		return []Group{}, Page{}, MalformedResponseError{err}
 	}
 
 	var groupList []Group
@@ -225,17 +326,45 @@ func (gs GroupsService) List(query Query, token string) ([]Group, error) {
 		groupList = append(groupList, newGroupFromResponse(gs.config, groupResponse))
 	}
 
-	return groupList, err
+	return groupList, newPage(response.StartIndex, response.ItemsPerPage, response.TotalResults), nil
+}
+
+// Iterate returns a GroupsIterator that walks every page of group resources matching the given
+// query, making requests to UAA as each page is needed. Query.StartIndex and Query.Count, when set,
+// control where iteration begins and how many resources are fetched per request.
+func (gs GroupsService) Iterate(query Query, token string) *GroupsIterator {
+	return gs.IterateWithContext(context.Background(), query, token)
+}
+
+// IterateWithContext is like Iterate, but uses the given context for the requests to UAA.
+func (gs GroupsService) IterateWithContext(ctx context.Context, query Query, token string) *GroupsIterator {
+	return &GroupsIterator{
+		pager: newPager(ctx, query),
+		list: func(ctx context.Context, query Query) ([]Group, Page, error) {
+			return gs.ListPageWithContext(ctx, query, token)
+		},
+	}
 }
 
 // Delete will make a request to UAA to delete the group resource with the matching id.
 // A token with the "scim.write" scope is required.
 func (gs GroupsService) Delete(id, token string) error {
-	_, err := newNetworkClient(gs.config).MakeRequest(network.Request{
+	return gs.DeleteWithContext(context.Background(), id, token)
+}
+
+// DeleteWithContext is like Delete, but uses the given context for the request to UAA.
+func (gs GroupsService) DeleteWithContext(ctx context.Context, id, token string) error {
+	authorization, err := tokenAuthorization(ctx, gs.config, token)
+	if err != nil {
+		return err
+	}
+
+	_, err = newNetworkClient(gs.config).MakeRequest(network.Request{
 		Method:                "DELETE",
 		Path:                  fmt.Sprintf("/Groups/%s", id),
-		Authorization:         network.NewTokenAuthorization(token),
+		Authorization:         authorization,
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return translateError(err)
@@ -1,6 +1,7 @@
 package warrant_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -47,7 +48,7 @@ var _ = Describe("GroupsService", func() {
 		})
 
 		Context("when the client does not have the scim.write scope", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"scim"},
@@ -61,12 +62,12 @@ var _ = Describe("GroupsService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.Create("some-group", t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
 		Context("when the client does not have the scim audience", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"banana"},
@@ -80,7 +81,7 @@ var _ = Describe("GroupsService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.Create("some-group", t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
@@ -135,8 +136,30 @@ var _ = Describe("GroupsService", func() {
 			Expect(fetchedGroup).To(Equal(updatedGroup))
 		})
 
+		Context("when the client has the groups.update scope", func() {
+			It("updates the group", func() {
+				c := warrant.Client{
+					ID:          "group-updater",
+					ResourceIDs: []string{"scim"},
+					Authorities: []string{"groups.update"},
+				}
+
+				err := clientsService.Create(c, "secret", token)
+				Expect(err).NotTo(HaveOccurred())
+
+				t, err := clientsService.GetToken(c.ID, "secret")
+				Expect(err).NotTo(HaveOccurred())
+
+				group.Description = "bananas and such"
+
+				updatedGroup, err := service.Update(group, t)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(updatedGroup.Description).To(Equal("bananas and such"))
+			})
+		})
+
 		Context("when the client does not have the scim.write scope", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"scim"},
@@ -150,12 +173,12 @@ var _ = Describe("GroupsService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.Update(group, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
 		Context("when the client does not have the scim audience", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"banana"},
@@ -169,7 +192,7 @@ var _ = Describe("GroupsService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.Update(group, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
@@ -220,7 +243,7 @@ var _ = Describe("GroupsService", func() {
 		})
 
 		Context("when the client does not have the scim.read scope", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"scim"},
@@ -234,12 +257,12 @@ var _ = Describe("GroupsService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.Get(createdGroup.ID, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
 		Context("when the client does not have the scim audience", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"banana"},
@@ -253,7 +276,7 @@ var _ = Describe("GroupsService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.Get(createdGroup.ID, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
@@ -281,6 +304,64 @@ var _ = Describe("GroupsService", func() {
 		})
 	})
 
+	Describe("AddMember", func() {
+		var group warrant.Group
+
+		BeforeEach(func() {
+			var err error
+			group, err = service.Create("some-group", token)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("adds the member to the group", func() {
+			member, err := service.AddMember(group.ID, "some-member-id", token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(member.Value).To(Equal("some-member-id"))
+
+			members, err := service.ListMembers(group.ID, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(ConsistOf(member))
+		})
+
+		Context("when the client has the groups.update scope", func() {
+			It("adds the member to the group", func() {
+				c := warrant.Client{
+					ID:          "group-updater",
+					ResourceIDs: []string{"scim"},
+					Authorities: []string{"groups.update"},
+				}
+
+				err := clientsService.Create(c, "secret", token)
+				Expect(err).NotTo(HaveOccurred())
+
+				t, err := clientsService.GetToken(c.ID, "secret")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = service.AddMember(group.ID, "some-member-id", t)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the client only has the scim.read scope", func() {
+			It("returns a forbidden error", func() {
+				c := warrant.Client{
+					ID:          "unauthorized",
+					ResourceIDs: []string{"scim"},
+					Authorities: []string{"scim.read"},
+				}
+
+				err := clientsService.Create(c, "secret", token)
+				Expect(err).NotTo(HaveOccurred())
+
+				t, err := clientsService.GetToken(c.ID, "secret")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = service.AddMember(group.ID, "some-member-id", t)
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
+			})
+		})
+	})
+
 	Describe("CheckMembership", func() {
 		var group warrant.Group
 		var member warrant.Member
@@ -316,7 +397,7 @@ var _ = Describe("GroupsService", func() {
 		})
 
 		Context("when the client does not have the scim.read scope", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"scim"},
@@ -330,12 +411,12 @@ var _ = Describe("GroupsService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, _, err = service.CheckMembership(group.ID, member.Value, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
 		Context("when the client does not have the scim audience", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"banana"},
@@ -349,7 +430,7 @@ var _ = Describe("GroupsService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, _, err = service.CheckMembership(group.ID, member.Value, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
@@ -395,7 +476,7 @@ var _ = Describe("GroupsService", func() {
 		})
 
 		Context("when the client does not have the scim.write scope", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"scim"},
@@ -409,12 +490,12 @@ var _ = Describe("GroupsService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				err = service.Delete(group.ID, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
 		Context("when the client does not have the scim audience", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"banana"},
@@ -428,7 +509,7 @@ var _ = Describe("GroupsService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				err = service.Delete(group.ID, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
@@ -466,6 +547,27 @@ var _ = Describe("GroupsService", func() {
 			Expect(groups[0].ID).To(Equal(group.ID))
 		})
 
+		It("finds groups using compound filter expressions", func() {
+			writeGroup, err := service.Create("banana.write", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = service.Create("eggplant.write", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			readGroup, err := service.Create("banana.read", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			groups, err := service.List(warrant.Query{
+				Filter: `displayName sw "banana." and (displayName co "write" or displayName co "read")`,
+				SortBy: "displayname",
+			}, token)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(groups).To(HaveLen(2))
+			Expect(groups[0].ID).To(Equal(readGroup.ID))
+			Expect(groups[1].ID).To(Equal(writeGroup.ID))
+		})
+
 		Context("when a group does not match filter", func() {
 			It("does not return that group", func() {
 				_, err := service.Create("banana.something-else", token)
@@ -507,8 +609,31 @@ var _ = Describe("GroupsService", func() {
 			})
 		})
 
+		Context("when the client has the uaa.admin scope", func() {
+			It("retrieves the groups", func() {
+				c := warrant.Client{
+					ID:          "uaa-admin",
+					ResourceIDs: []string{"scim"},
+					Authorities: []string{"uaa.admin"},
+				}
+
+				err := clientsService.Create(c, "secret", token)
+				Expect(err).NotTo(HaveOccurred())
+
+				t, err := clientsService.GetToken(c.ID, "secret")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = service.Create("banana.write", t)
+				Expect(err).NotTo(HaveOccurred())
+
+				groups, err := service.List(warrant.Query{}, t)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(groups).To(HaveLen(1))
+			})
+		})
+
 		Context("when the client does not have the scim.read scope", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"scim"},
@@ -522,12 +647,12 @@ var _ = Describe("GroupsService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.List(warrant.Query{}, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
 		Context("when the client does not have the scim audience", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"banana"},
@@ -541,7 +666,7 @@ var _ = Describe("GroupsService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.List(warrant.Query{}, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
@@ -576,4 +701,54 @@ var _ = Describe("GroupsService", func() {
 			})
 		})
 	})
+
+	Describe("Iterate", func() {
+		It("walks every page of groups", func() {
+			var ids []string
+			for _, name := range []string{"banana.read", "banana.write", "banana.delete"} {
+				group, err := service.Create(name, token)
+				Expect(err).NotTo(HaveOccurred())
+
+				ids = append(ids, group.ID)
+			}
+
+			iterator := service.Iterate(warrant.Query{Count: 2}, token)
+
+			var groups []warrant.Group
+			for iterator.Next() {
+				groups = append(groups, iterator.Group())
+			}
+			Expect(iterator.Err()).NotTo(HaveOccurred())
+
+			Expect(groups).To(HaveLen(3))
+			Expect([]string{groups[0].ID, groups[1].ID, groups[2].ID}).To(Equal(ids))
+		})
+	})
+
+	Describe("GetWithContext", func() {
+		It("aborts the request when the context is canceled", func() {
+			unblock := make(chan struct{})
+			slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				<-unblock
+			}))
+			defer slowServer.Close()
+			defer close(unblock)
+
+			service = warrant.NewGroupsService(warrant.Config{
+				Host:          slowServer.URL,
+				SkipVerifySSL: true,
+				TraceWriter:   TraceWriter,
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				cancel()
+			}()
+
+			_, err := service.GetWithContext(ctx, "some-group-id", token)
+			Expect(err).To(BeAssignableToTypeOf(warrant.UnknownError{}))
+			Expect(err.Error()).To(ContainSubstring("context canceled"))
+		})
+	})
 })
@@ -0,0 +1,21 @@
+package warrant
+
+import "net/http"
+
+// Interceptor is called for every HTTP roundtrip made to UAA by the services
+// of this library, including those that follow redirects. An Interceptor may
+// modify the request before passing it on by calling next.RoundTrip, and may
+// inspect or modify the response that is returned. It may also return a
+// response or error without calling next at all.
+type Interceptor interface {
+	Intercept(req *http.Request, next http.RoundTripper) (*http.Response, error)
+}
+
+// InterceptorFunc is an adapter that allows an ordinary function to be used
+// as an Interceptor.
+type InterceptorFunc func(req *http.Request, next http.RoundTripper) (*http.Response, error)
+
+// Intercept calls f(req, next).
+func (f InterceptorFunc) Intercept(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	return f(req, next)
+}
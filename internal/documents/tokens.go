@@ -1,5 +1,7 @@
 package documents
 
+import "encoding/json"
+
 // TokenResponse represents the JSON transport data structure
 // for a request that returns a token value.
 type TokenResponse struct {
@@ -7,6 +9,10 @@ type TokenResponse struct {
 	// with UAA-based services.
 	AccessToken string `json:"access_token"`
 
+	// RefreshToken is the token string that can be exchanged
+	// for a new access token using the "refresh_token" grant.
+	RefreshToken string `json:"refresh_token,omitempty"`
+
 	// TokenType describes the type of token returned.
 	// This value is always "Bearer".
 	TokenType string `json:"token_type"`
@@ -54,8 +60,96 @@ type TokenKeyResponse struct {
 	Use string `json:"use"`
 
 	// N is the public/private modulus for the key.
-	N string `json:"n"`
+	N string `json:"n,omitempty"`
 
 	// E is the public exponent for the key.
-	E string `json:"e"`
+	E string `json:"e,omitempty"`
+
+	// Crv identifies the curve of an elliptic curve or
+	// Edwards curve key, as in "P-256" or "Ed25519".
+	Crv string `json:"crv,omitempty"`
+
+	// X is the x coordinate of an elliptic curve key, or
+	// the public key of an Edwards curve key.
+	X string `json:"x,omitempty"`
+
+	// Y is the y coordinate of an elliptic curve key.
+	Y string `json:"y,omitempty"`
+}
+
+// CheckTokenResponse represents the JSON transport data structure
+// for a response from UAA to a request to check a token.
+type CheckTokenResponse struct {
+	// Active indicates whether the token is currently valid.
+	Active bool `json:"active"`
+
+	// UserID is the unique identifier of the user to whom the token
+	// was granted.
+	UserID string `json:"user_id,omitempty"`
+
+	// ClientID is the unique identifier of the client to whom the
+	// token was granted.
+	ClientID string `json:"client_id"`
+
+	// Scope is the list of permission values granted to the token.
+	Scope []string `json:"scope"`
+
+	// Authorities is the list of permission values granted to the
+	// client.
+	Authorities []string `json:"authorities,omitempty"`
+
+	// Audiences is the list of resource servers for which the token
+	// is intended.
+	Audiences []string `json:"aud"`
+
+	// Issuer is the URL to the issuer of the token.
+	Issuer string `json:"iss"`
+
+	// JTI is the unique identifier for this JWT token.
+	JTI string `json:"jti"`
+
+	// ExpiresAt is the time, in seconds since the epoch, at which
+	// the token expires.
+	ExpiresAt int64 `json:"exp,omitempty"`
+
+	// IssuedAt is the time, in seconds since the epoch, at which
+	// the token was issued.
+	IssuedAt int64 `json:"iat,omitempty"`
+}
+
+// ErrorResponse represents the JSON transport data structure
+// for an error response from UAA.
+type ErrorResponse struct {
+	// Error is the code identifying the kind of error.
+	Error string `json:"error"`
+
+	// ErrorDescription is a human readable description of the error.
+	ErrorDescription string `json:"error_description"`
+
+	// Message is a human readable description of the error,
+	// returned by some UAA SCIM endpoints.
+	Message string `json:"message,omitempty"`
+
+	// Status is the HTTP status code included in SCIM 2.0
+	// errors, given as either a string or a number.
+	Status json.RawMessage `json:"status,omitempty"`
+
+	// Detail is a human readable description of a SCIM 2.0
+	// error.
+	Detail string `json:"detail,omitempty"`
+
+	// Errors is the list of errors included in a SCIM 1.1
+	// error.
+	Errors []SCIMError `json:"Errors,omitempty"`
+}
+
+// SCIMError represents an entry in the list of errors of
+// a SCIM 1.1 error response.
+type SCIMError struct {
+	// Code is the HTTP status code of the error.
+	Code string `json:"code"`
+
+	// Description is a human readable description of the
+	// error.
+	Description string `json:"description"`
 }
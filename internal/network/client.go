@@ -1,9 +1,11 @@
 package network
 
 import (
+	"context"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 )
 
 // Client provides the ability to make HTTP requests.
@@ -20,9 +22,59 @@ type Config struct {
 	// will be validated when requests are made to servers secured by HTTPS.
 	SkipVerifySSL bool
 
+	// CACerts is a PEM encoded bundle of the certificate authorities
+	// trusted to sign the certificate of the remote server. This is an
+	// optional field.
+	CACerts []byte
+
+	// CACertsFile is the path to a PEM encoded bundle of certificate
+	// authorities, trusted in addition to those in CACerts. This is an
+	// optional field.
+	CACertsFile string
+
+	// ClientCert and ClientKey are the PEM encoded certificate and private
+	// key presented to servers that request a client certificate. These
+	// are optional fields.
+	ClientCert []byte
+	ClientKey  []byte
+
+	// ClientCertFile and ClientKeyFile are the paths to a PEM encoded
+	// certificate and private key, used in place of ClientCert and
+	// ClientKey. These files, and CACertsFile, are read again when
+	// their size or modification time changes, so that rotated certificates
+	// are picked up. These are optional fields.
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// MinTLSVersion is the minimum version of TLS that will be negotiated
+	// with the remote server, such as tls.VersionTLS12. This is an
+	// optional field.
+	MinTLSVersion uint16
+
+	// Transport is the http.RoundTripper used to make requests. When this
+	// optional field is set, the TLS settings above are ignored.
+	Transport http.RoundTripper
+
+	// Interceptors are called, in order, for every roundtrip made by the
+	// Client. This is an optional field.
+	Interceptors []Interceptor
+
+	// Retry describes how requests that fail with a transient error are
+	// retried. Requests are attempted once when this field is not set.
+	Retry RetryPolicy
+
 	// TraceWriter is an io.Writer to which trace information can be written.
 	// This is an optional field.
 	TraceWriter io.Writer
+
+	// TraceFormat is the format in which trace information is written to the
+	// TraceWriter. This value defaults to TraceFormatText.
+	TraceFormat TraceFormat
+
+	// TraceSecrets disables the redaction of credentials, such as tokens,
+	// passwords, and client secrets, from the trace information written in
+	// the TraceFormatText format.
+	TraceSecrets bool
 }
 
 // Request describes the requested operation to commit against the remote
@@ -64,6 +116,12 @@ type Request struct {
 	// response codes should be followed, or treated as terminal responses. The client
 	// will make a single roundtrip in the case that this value is set to true.
 	DoNotFollowRedirects bool
+
+	// Context is an optional context.Context that governs the lifetime of the
+	// request. When the context is canceled or its deadline is exceeded, any
+	// in-flight HTTP roundtrip is aborted. A nil Context is treated as
+	// context.Background().
+	Context context.Context
 }
 
 // Response describes the response information provided by the remote host.
@@ -86,19 +144,56 @@ func NewClient(config Config) Client {
 }
 
 // MakeRequest initiates a request to the remote host, returning a response and
-// possible error.
+// possible error. The request is attempted again according to the RetryPolicy
+// of the Client when it fails with a transient error.
 func (c Client) MakeRequest(req Request) (Response, error) {
 	if req.AcceptableStatusCodes == nil {
 		panic("acceptable status codes for this request were not set")
 	}
 
+	transport := c.config.Transport
+	if transport == nil {
+		var err error
+		transport, err = transportFor(c.config)
+		if err != nil {
+			return Response{}, newRequestConfigurationError(err)
+		}
+	}
+	transport = intercept(transport, c.config.Interceptors)
+
+	ctx := req.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	correlationID := newCorrelationID()
+	for attempts := 1; ; attempts++ {
+		start := time.Now()
+		resp, err := c.roundTrip(transport, req)
+		c.printAttempt(req, correlationID, attempts, start, resp, err)
+
+		delay, retry := c.config.Retry.retryDelay(ctx, req.Method, attempts, resp, err)
+		if !retry {
+			if err != nil {
+				return Response{}, err
+			}
+
+			return c.handleResponse(req, resp)
+		}
+
+		if err := wait(ctx, delay); err != nil {
+			return Response{}, newRequestHTTPError(err)
+		}
+	}
+}
+
+func (c Client) roundTrip(transport http.RoundTripper, req Request) (Response, error) {
 	request, err := c.buildRequest(req)
 	if err != nil {
 		return Response{}, err
 	}
 
 	var resp *http.Response
-	transport := buildTransport(c.config.SkipVerifySSL)
 	if req.DoNotFollowRedirects {
 		resp, err = transport.RoundTrip(request)
 	} else {
@@ -115,11 +210,14 @@ func (c Client) MakeRequest(req Request) (Response, error) {
 		return Response{}, newResponseReadError(err)
 	}
 
-	return c.handleResponse(req, Response{
+	response := Response{
 		Code:    resp.StatusCode,
 		Body:    responseBody,
 		Headers: resp.Header,
-	})
+	}
+	c.printResponse(response)
+
+	return response, nil
 }
 
 func (c Client) buildRequest(req Request) (*http.Request, error) {
@@ -133,11 +231,17 @@ func (c Client) buildRequest(req Request) (*http.Request, error) {
 		}
 	}
 
+	ctx := req.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	requestURL := c.config.Host + req.Path
 	request, err := http.NewRequest(req.Method, requestURL, bodyReader)
 	if err != nil {
 		return &http.Request{}, newRequestConfigurationError(err)
 	}
+	request = request.WithContext(ctx)
 
 	if req.Authorization != nil {
 		request.Header.Set("Authorization", req.Authorization.Authorization())
@@ -158,8 +262,6 @@ func (c Client) buildRequest(req Request) (*http.Request, error) {
 }
 
 func (c Client) handleResponse(request Request, response Response) (Response, error) {
-	c.printResponse(response)
-
 	for _, acceptableCode := range request.AcceptableStatusCodes {
 		if response.Code == acceptableCode {
 			return response, nil
@@ -1,12 +1,15 @@
 package network_test
 
 import (
+	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pivotal-cf-experimental/warrant/internal/network"
 
@@ -16,6 +19,18 @@ import (
 
 var unsupportedJSONType = func() {}
 
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type interceptorFunc func(req *http.Request, next http.RoundTripper) (*http.Response, error)
+
+func (f interceptorFunc) Intercept(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	return f(req, next)
+}
+
 type Request struct {
 	Body   string
 	Header http.Header
@@ -170,6 +185,158 @@ var _ = Describe("Client", func() {
 			})
 		})
 
+		Context("when a context is provided", func() {
+			It("aborts the request when the context is canceled", func() {
+				unblock := make(chan struct{})
+				slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					<-unblock
+				}))
+				defer slowServer.Close()
+				defer close(unblock)
+
+				client = network.NewClient(network.Config{
+					Host:          slowServer.URL,
+					SkipVerifySSL: true,
+					TraceWriter:   TraceWriter,
+				})
+
+				ctx, cancel := context.WithCancel(context.Background())
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					cancel()
+				}()
+
+				_, err := client.MakeRequest(network.Request{
+					Method:                "GET",
+					Path:                  "/path",
+					AcceptableStatusCodes: []int{http.StatusOK},
+					Context:               ctx,
+				})
+				Expect(err).To(BeAssignableToTypeOf(network.RequestHTTPError{}))
+				Expect(err.Error()).To(ContainSubstring("context canceled"))
+			})
+
+			It("does not make the request when the context is already done", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				_, err := client.MakeRequest(network.Request{
+					Method:                "GET",
+					Path:                  "/path",
+					Authorization:         network.NewTokenAuthorization(token),
+					AcceptableStatusCodes: []int{http.StatusOK},
+					Context:               ctx,
+				})
+				Expect(err).To(BeAssignableToTypeOf(network.RequestHTTPError{}))
+				Expect(receivedRequest.Header).To(BeNil())
+			})
+		})
+
+		Context("when a transport is configured", func() {
+			It("makes requests using the transport", func() {
+				var requestedURL string
+				client = network.NewClient(network.Config{
+					Host:        "http://uaa.example.com",
+					TraceWriter: TraceWriter,
+					Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+						requestedURL = req.URL.String()
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       ioutil.NopCloser(strings.NewReader(`{"hello": "goodbye"}`)),
+							Header:     http.Header{},
+						}, nil
+					}),
+				})
+
+				resp, err := client.MakeRequest(network.Request{
+					Method:                "GET",
+					Path:                  "/path",
+					AcceptableStatusCodes: []int{http.StatusOK},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Body).To(MatchJSON(`{"hello": "goodbye"}`))
+				Expect(requestedURL).To(Equal("http://uaa.example.com/path"))
+			})
+		})
+
+		Context("when interceptors are configured", func() {
+			var calls []string
+
+			BeforeEach(func() {
+				calls = []string{}
+
+				interceptor := func(name string) network.Interceptor {
+					return interceptorFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+						calls = append(calls, name+" request")
+						req.Header.Add("X-Interceptors", name)
+
+						resp, err := next.RoundTrip(req)
+						if err != nil {
+							return nil, err
+						}
+
+						calls = append(calls, name+" response")
+						resp.Header.Add("X-Interceptors", name)
+
+						return resp, nil
+					})
+				}
+
+				client = network.NewClient(network.Config{
+					Host:          fakeServer.URL,
+					SkipVerifySSL: true,
+					TraceWriter:   TraceWriter,
+					Interceptors:  []network.Interceptor{interceptor("first"), interceptor("second")},
+				})
+			})
+
+			It("passes each request and response through the interceptors in order", func() {
+				resp, err := client.MakeRequest(network.Request{
+					Method:                "GET",
+					Path:                  "/path",
+					AcceptableStatusCodes: []int{http.StatusOK},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(calls).To(Equal([]string{"first request", "second request", "second response", "first response"}))
+				Expect(receivedRequest.Header["X-Interceptors"]).To(Equal([]string{"first", "second"}))
+				Expect(resp.Headers["X-Interceptors"]).To(Equal([]string{"second", "first"}))
+			})
+
+			It("passes requests through the interceptors when redirects are not followed", func() {
+				_, err := client.MakeRequest(network.Request{
+					Method:                "GET",
+					Path:                  "/path",
+					AcceptableStatusCodes: []int{http.StatusOK},
+					DoNotFollowRedirects:  true,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(calls).To(HaveLen(4))
+			})
+
+			It("returns a RequestHTTPError when an interceptor fails", func() {
+				client = network.NewClient(network.Config{
+					Host:          fakeServer.URL,
+					SkipVerifySSL: true,
+					TraceWriter:   TraceWriter,
+					Interceptors: []network.Interceptor{
+						interceptorFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+							return nil, errors.New("intercepted")
+						}),
+					},
+				})
+
+				_, err := client.MakeRequest(network.Request{
+					Method:                "GET",
+					Path:                  "/path",
+					AcceptableStatusCodes: []int{http.StatusOK},
+				})
+				Expect(err).To(BeAssignableToTypeOf(network.RequestHTTPError{}))
+				Expect(err).To(MatchError(ContainSubstring("intercepted")))
+				Expect(receivedRequest.Header).To(BeNil())
+			})
+		})
+
 		Context("Headers", func() {
 			Context("authorization", func() {
 				It("does not include Authorization header when there is no authorization", func() {
@@ -1,6 +1,9 @@
 package network
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+)
 
 // RequestBodyEncodeError indicates that the body passed in
 // the Request cannot be encoded.
@@ -17,6 +20,11 @@ func (e RequestBodyEncodeError) Error() string {
 	return fmt.Sprintf("Warrant RequestBodyMarshalError: %v", e.err)
 }
 
+// Unwrap returns the error that caused the RequestBodyEncodeError.
+func (e RequestBodyEncodeError) Unwrap() error {
+	return e.err
+}
+
 // RequestConfigurationError indicates that an HTTP request
 // cannot be created.
 type RequestConfigurationError struct {
@@ -32,6 +40,11 @@ func (e RequestConfigurationError) Error() string {
 	return fmt.Sprintf("Warrant RequestConfigurationError: %v", e.err)
 }
 
+// Unwrap returns the error that caused the RequestConfigurationError.
+func (e RequestConfigurationError) Unwrap() error {
+	return e.err
+}
+
 // RequestHTTPError indicates that some portion of the
 // HTTP request to the remote has failed.
 type RequestHTTPError struct {
@@ -47,6 +60,11 @@ func (e RequestHTTPError) Error() string {
 	return fmt.Sprintf("Warrant RequestHTTPError: %v", e.err)
 }
 
+// Unwrap returns the error that caused the RequestHTTPError.
+func (e RequestHTTPError) Unwrap() error {
+	return e.err
+}
+
 // ResponseReadError indicates that the response body could not be read.
 type ResponseReadError struct {
 	err error
@@ -61,6 +79,11 @@ func (e ResponseReadError) Error() string {
 	return fmt.Sprintf("Warrant ResponseReadError: %v", e.err)
 }
 
+// Unwrap returns the error that caused the ResponseReadError.
+func (e ResponseReadError) Unwrap() error {
+	return e.err
+}
+
 // UnexpectedStatusError indicates that the response status code
 // that was returned from the remote host was not in the list of
 // AcceptableStatusCodes specified in the Request.
@@ -81,6 +104,12 @@ func (e UnexpectedStatusError) Error() string {
 	return fmt.Sprintf("Warrant UnexpectedStatusError: %d %s", e.Status, e.Body)
 }
 
+// Response returns the status code and body of the response that caused the
+// UnexpectedStatusError.
+func (e UnexpectedStatusError) Response() Response {
+	return Response{Code: e.Status, Body: e.Body}
+}
+
 // NotFoundError indicates that the requested API endpoint or resource
 // could not be found.
 type NotFoundError struct {
@@ -96,6 +125,12 @@ func (e NotFoundError) Error() string {
 	return fmt.Sprintf("Warrant NotFoundError: %s", e.message)
 }
 
+// Response returns the status code and body of the response that caused the
+// NotFoundError.
+func (e NotFoundError) Response() Response {
+	return Response{Code: http.StatusNotFound, Body: e.message}
+}
+
 // UnauthorizedError indicates that the request could not be
 // completed because the authorization that was provided does
 // not meet the expected permissions requirements from UAA.
@@ -112,6 +147,12 @@ func (e UnauthorizedError) Error() string {
 	return fmt.Sprintf("Warrant UnauthorizedError: %s", e.message)
 }
 
+// Response returns the status code and body of the response that caused the
+// UnauthorizedError.
+func (e UnauthorizedError) Response() Response {
+	return Response{Code: http.StatusUnauthorized, Body: e.message}
+}
+
 // ForbiddenError indicates that the request could not be
 // completed because the authorization that was provided does
 // not have the sufficient scope requirements from UAA.
@@ -131,3 +172,9 @@ func newForbiddenError(statusCode int, body []byte) ForbiddenError {
 func (e ForbiddenError) Error() string {
 	return fmt.Sprintf("Warrant ForbiddenError: %s", e.Body)
 }
+
+// Response returns the status code and body of the response that caused the
+// ForbiddenError.
+func (e ForbiddenError) Response() Response {
+	return Response{Code: e.StatusCode, Body: e.Body}
+}
@@ -1,7 +1,27 @@
 package network
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+)
 
 func BuildTransport(skipVerifySSL bool) http.RoundTripper {
 	return buildTransport(skipVerifySSL)
 }
+
+func TransportFor(config Config) (http.RoundTripper, error) {
+	return transportFor(config)
+}
+
+func TLSTransportCount() int {
+	_tlsTransportsMutex.Lock()
+	defer _tlsTransportsMutex.Unlock()
+
+	return len(_tlsTransports)
+}
+
+const MaxTLSTransports = maxTLSTransports
+
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	return p.backoff(retry)
+}
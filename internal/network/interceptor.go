@@ -0,0 +1,32 @@
+package network
+
+import "net/http"
+
+// Interceptor is called for every HTTP roundtrip made by a Client. It may
+// modify the request before passing it to the next RoundTripper, and inspect
+// or modify the response that is returned.
+type Interceptor interface {
+	Intercept(req *http.Request, next http.RoundTripper) (*http.Response, error)
+}
+
+type interceptingTransport struct {
+	interceptor Interceptor
+	next        http.RoundTripper
+}
+
+func (t interceptingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.interceptor.Intercept(req, t.next)
+}
+
+// intercept wraps the transport with the given interceptors so that the
+// first interceptor is the first to see each request.
+func intercept(transport http.RoundTripper, interceptors []Interceptor) http.RoundTripper {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		transport = interceptingTransport{
+			interceptor: interceptors[i],
+			next:        transport,
+		}
+	}
+
+	return transport
+}
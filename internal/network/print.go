@@ -2,14 +2,79 @@ package network
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
+// TraceFormat is the format in which a Client writes trace information to
+// its TraceWriter.
+type TraceFormat int
+
+const (
+	// TraceFormatText writes each request and response, including their
+	// headers and bodies, as lines of text.
+	TraceFormatText TraceFormat = iota
+
+	// TraceFormatJSON writes a JSON object on a single line for each attempt
+	// of a request, including its method, path, response status, duration,
+	// and the correlation ID shared by all attempts of the request.
+	TraceFormatJSON
+)
+
+const redacted = "[REDACTED]"
+
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+var sensitiveFields = map[string]bool{
+	"password":      true,
+	"oldPassword":   true,
+	"passcode":      true,
+	"client_secret": true,
+	"code":          true,
+	"code_verifier": true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"assertion":     true,
+}
+
+type traceEntry struct {
+	Time          string  `json:"time"`
+	CorrelationID string  `json:"correlation_id"`
+	Attempt       int     `json:"attempt"`
+	Method        string  `json:"method"`
+	Path          string  `json:"path"`
+	Status        int     `json:"status,omitempty"`
+	DurationMS    float64 `json:"duration_ms"`
+	Error         string  `json:"error,omitempty"`
+}
+
+func newCorrelationID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(id)
+}
+
 func (c Client) printRequest(request *http.Request) {
-	if c.config.TraceWriter != nil {
+	if c.config.TraceWriter != nil && c.config.TraceFormat == TraceFormatText {
 		logger := log.New(c.config.TraceWriter, "", 0)
 
 		bodyCopy := bytes.NewBuffer([]byte{})
@@ -23,14 +88,167 @@ func (c Client) printRequest(request *http.Request) {
 			request.Body = ioutil.NopCloser(body)
 		}
 
-		logger.Printf("REQUEST: %s %s %s %v\n", request.Method, request.URL, bodyCopy.String(), request.Header)
+		requestURL := *request.URL
+		requestBody := bodyCopy.Bytes()
+		header := request.Header
+		if !c.config.TraceSecrets {
+			requestURL.RawQuery = redactForm(requestURL.RawQuery)
+			requestBody = redactBody(request.Header.Get("Content-Type"), requestBody)
+			header = redactHeader(header)
+		}
+
+		logger.Printf("REQUEST: %s %s %s %v\n", request.Method, &requestURL, requestBody, header)
 	}
 }
 
 func (c Client) printResponse(resp Response) {
-	if c.config.TraceWriter != nil {
+	if c.config.TraceWriter != nil && c.config.TraceFormat == TraceFormatText {
 		logger := log.New(c.config.TraceWriter, "", 0)
 
-		logger.Printf("RESPONSE: %d %s %+v\n", resp.Code, resp.Body, resp.Headers)
+		body := resp.Body
+		header := resp.Headers
+		if !c.config.TraceSecrets {
+			body = redactBody(resp.Headers.Get("Content-Type"), body)
+			header = redactHeader(header)
+		}
+
+		logger.Printf("RESPONSE: %d %s %+v\n", resp.Code, body, header)
+	}
+}
+
+func (c Client) printAttempt(req Request, correlationID string, attempt int, start time.Time, resp Response, err error) {
+	if c.config.TraceWriter != nil && c.config.TraceFormat == TraceFormatJSON {
+		entry := traceEntry{
+			Time:          start.UTC().Format(time.RFC3339Nano),
+			CorrelationID: correlationID,
+			Attempt:       attempt,
+			Method:        req.Method,
+			Path:          req.Path,
+			Status:        resp.Code,
+			DurationMS:    float64(time.Since(start)) / float64(time.Millisecond),
+		}
+
+		if parsed, parseErr := url.Parse(req.Path); parseErr == nil {
+			entry.Path = parsed.Path
+		}
+
+		if err != nil {
+			if !c.config.TraceSecrets {
+				err = redactError(err)
+			}
+			entry.Error = err.Error()
+		}
+
+		line, err := json.Marshal(entry)
+		if err != nil {
+			panic(err)
+		}
+
+		c.config.TraceWriter.Write(append(line, '\n'))
+	}
+}
+
+// redactError redacts the query of the URL that the net/http package includes
+// in the errors of failed requests.
+func redactError(err error) error {
+	httpErr, ok := err.(RequestHTTPError)
+	if !ok {
+		return err
+	}
+
+	urlErr, ok := httpErr.err.(*url.Error)
+	if !ok {
+		return err
 	}
+
+	redactedURL := redacted
+	if parsed, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+		parsed.RawQuery = redactForm(parsed.RawQuery)
+		redactedURL = parsed.String()
+	}
+
+	return newRequestHTTPError(&url.Error{
+		Op:  urlErr.Op,
+		URL: redactedURL,
+		Err: urlErr.Err,
+	})
+}
+
+func redactHeader(header http.Header) http.Header {
+	redactedHeader := http.Header{}
+	for key, values := range header {
+		redactedHeader[key] = values
+	}
+
+	for _, key := range sensitiveHeaders {
+		if _, ok := redactedHeader[key]; ok {
+			redactedHeader[key] = []string{redacted}
+		}
+	}
+
+	return redactedHeader
+}
+
+// redactBody redacts the sensitive fields of form encoded bodies, and of JSON
+// bodies regardless of their content type, as not every server labels its
+// JSON responses as such.
+func redactBody(contentType string, body []byte) []byte {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/x-www-form-urlencoded" {
+		return []byte(redactForm(string(body)))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil || decoder.More() {
+		return body
+	}
+
+	redactedBody, err := json.Marshal(redactJSON(document))
+	if err != nil {
+		return body
+	}
+
+	return redactedBody
+}
+
+func redactForm(form string) string {
+	values, err := url.ParseQuery(form)
+	if err != nil {
+		return form
+	}
+
+	var changed bool
+	for key := range values {
+		if sensitiveFields[key] {
+			values[key] = []string{redacted}
+			changed = true
+		}
+	}
+
+	if !changed {
+		return form
+	}
+
+	return strings.Replace(values.Encode(), url.QueryEscape(redacted), redacted, -1)
+}
+
+func redactJSON(document interface{}) interface{} {
+	switch d := document.(type) {
+	case map[string]interface{}:
+		for key, value := range d {
+			if sensitiveFields[key] {
+				d[key] = redacted
+			} else {
+				d[key] = redactJSON(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range d {
+			d[i] = redactJSON(value)
+		}
+	}
+
+	return document
 }
@@ -0,0 +1,219 @@
+package network_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/network"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("tracing requests", func() {
+	var (
+		server *httptest.Server
+		trace  *bytes.Buffer
+		config network.Config
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+			w.Header().Set("Set-Cookie", "JSESSIONID=some-session")
+			w.Write([]byte(`{"access_token":"some-access-token","refresh_token":"some-refresh-token","access_token_validity":3600}`))
+		}))
+
+		trace = bytes.NewBuffer([]byte{})
+		config = network.Config{
+			Host:        server.URL,
+			TraceWriter: trace,
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("when tracing as text", func() {
+		It("redacts credentials from form requests", func() {
+			_, err := network.NewClient(config).MakeRequest(network.Request{
+				Method:        "POST",
+				Path:          "/oauth/token?token=some-query-token",
+				Authorization: network.NewBasicAuthorization("some-client", "some-secret"),
+				Body: network.NewFormRequestBody(url.Values{
+					"grant_type":    []string{"password"},
+					"username":      []string{"some-user"},
+					"password":      []string{"some-password"},
+					"client_secret": []string{"some-client-secret"},
+				}),
+				AcceptableStatusCodes: []int{http.StatusOK},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			output := trace.String()
+			Expect(output).To(ContainSubstring("REQUEST: POST " + server.URL + "/oauth/token?token=[REDACTED] "))
+			Expect(output).To(ContainSubstring("client_secret=[REDACTED]&grant_type=password&password=[REDACTED]&username=some-user"))
+			Expect(output).To(ContainSubstring("Authorization:[[REDACTED]]"))
+			Expect(output).To(ContainSubstring(`RESPONSE: 200 {"access_token":"[REDACTED]","access_token_validity":3600,"refresh_token":"[REDACTED]"}`))
+			Expect(output).To(ContainSubstring("Set-Cookie:[[REDACTED]]"))
+
+			for _, secret := range []string{"some-query-token", "some-secret", "some-password", "some-client-secret", "some-access-token", "some-refresh-token", "some-session"} {
+				Expect(output).NotTo(ContainSubstring(secret))
+			}
+		})
+
+		It("redacts credentials from JSON requests", func() {
+			_, err := network.NewClient(config).MakeRequest(network.Request{
+				Method:        "PUT",
+				Path:          "/Users/some-user-id/password",
+				Authorization: network.NewTokenAuthorization("some-token"),
+				Body: network.NewJSONRequestBody(map[string]interface{}{
+					"password":    "some-password",
+					"oldPassword": "some-old-password",
+					"emails":      []map[string]string{{"value": "some-user@example.com"}},
+				}),
+				AcceptableStatusCodes: []int{http.StatusOK},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			output := trace.String()
+			Expect(output).To(ContainSubstring(`{"emails":[{"value":"some-user@example.com"}],"oldPassword":"[REDACTED]","password":"[REDACTED]"}`))
+			Expect(output).NotTo(ContainSubstring("some-token"))
+			Expect(output).NotTo(ContainSubstring("some-password"))
+		})
+
+		It("includes credentials when TraceSecrets is set", func() {
+			config.TraceSecrets = true
+
+			_, err := network.NewClient(config).MakeRequest(network.Request{
+				Method:        "POST",
+				Path:          "/oauth/token",
+				Authorization: network.NewBasicAuthorization("some-client", "some-secret"),
+				Body: network.NewFormRequestBody(url.Values{
+					"password": []string{"some-password"},
+				}),
+				AcceptableStatusCodes: []int{http.StatusOK},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			output := trace.String()
+			Expect(output).To(ContainSubstring("password=some-password"))
+			Expect(output).To(ContainSubstring("some-access-token"))
+			Expect(output).NotTo(ContainSubstring("[REDACTED]"))
+		})
+	})
+
+	Context("when tracing as JSON", func() {
+		BeforeEach(func() {
+			config.TraceFormat = network.TraceFormatJSON
+		})
+
+		It("writes a line for each attempt of a request", func() {
+			var attempts int
+			flakyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				attempts++
+				if attempts == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer flakyServer.Close()
+
+			config.Host = flakyServer.URL
+			config.Retry = network.RetryPolicy{
+				MaxAttempts:    2,
+				InitialBackoff: time.Millisecond,
+			}
+
+			_, err := network.NewClient(config).MakeRequest(network.Request{
+				Method:                "GET",
+				Path:                  "/Users?filter=userName+eq+%22some-user%22",
+				Authorization:         network.NewTokenAuthorization("some-token"),
+				AcceptableStatusCodes: []int{http.StatusOK},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			lines := strings.Split(strings.TrimSpace(trace.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+
+			var entries []map[string]interface{}
+			for _, line := range lines {
+				var entry map[string]interface{}
+				Expect(json.Unmarshal([]byte(line), &entry)).To(Succeed())
+				entries = append(entries, entry)
+			}
+
+			Expect(entries[0]).To(HaveKeyWithValue("method", "GET"))
+			Expect(entries[0]).To(HaveKeyWithValue("path", "/Users"))
+			Expect(entries[0]).To(HaveKeyWithValue("status", BeNumerically("==", http.StatusServiceUnavailable)))
+			Expect(entries[0]).To(HaveKeyWithValue("attempt", BeNumerically("==", 1)))
+			Expect(entries[0]).To(HaveKeyWithValue("duration_ms", BeNumerically(">=", 0)))
+			Expect(entries[0]).To(HaveKey("time"))
+			Expect(entries[0]["correlation_id"]).To(MatchRegexp(`^[0-9a-f]{32}$`))
+
+			Expect(entries[1]).To(HaveKeyWithValue("status", BeNumerically("==", http.StatusOK)))
+			Expect(entries[1]).To(HaveKeyWithValue("attempt", BeNumerically("==", 2)))
+			Expect(entries[1]["correlation_id"]).To(Equal(entries[0]["correlation_id"]))
+
+			Expect(trace.String()).NotTo(ContainSubstring("some-token"))
+			Expect(trace.String()).NotTo(ContainSubstring("some-user"))
+		})
+
+		It("uses a different correlation ID for each request", func() {
+			client := network.NewClient(config)
+			for i := 0; i < 2; i++ {
+				_, err := client.MakeRequest(network.Request{
+					Method:                "GET",
+					Path:                  "/path",
+					AcceptableStatusCodes: []int{http.StatusOK},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			lines := strings.Split(strings.TrimSpace(trace.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+
+			var first, second map[string]interface{}
+			Expect(json.Unmarshal([]byte(lines[0]), &first)).To(Succeed())
+			Expect(json.Unmarshal([]byte(lines[1]), &second)).To(Succeed())
+			Expect(first["correlation_id"]).NotTo(Equal(second["correlation_id"]))
+		})
+
+		It("includes the error of attempts that fail", func() {
+			config.Host = "http://127.0.0.1:0"
+
+			_, err := network.NewClient(config).MakeRequest(network.Request{
+				Method:                "GET",
+				Path:                  "/path",
+				AcceptableStatusCodes: []int{http.StatusOK},
+			})
+			Expect(err).To(HaveOccurred())
+
+			var entry map[string]interface{}
+			Expect(json.Unmarshal(trace.Bytes(), &entry)).To(Succeed())
+			Expect(entry).To(HaveKeyWithValue("error", ContainSubstring("Warrant RequestHTTPError")))
+			Expect(entry).NotTo(HaveKey("status"))
+		})
+
+		It("redacts credentials from the URL in the error of attempts that fail", func() {
+			config.Host = "http://127.0.0.1:0"
+
+			_, err := network.NewClient(config).MakeRequest(network.Request{
+				Method:                "GET",
+				Path:                  "/check_token?token=some-query-token&scopes=openid",
+				AcceptableStatusCodes: []int{http.StatusOK},
+			})
+			Expect(err).To(HaveOccurred())
+
+			var entry map[string]interface{}
+			Expect(json.Unmarshal(trace.Bytes(), &entry)).To(Succeed())
+			Expect(entry).To(HaveKeyWithValue("error", ContainSubstring("/check_token?scopes=openid&token=[REDACTED]")))
+			Expect(trace.String()).NotTo(ContainSubstring("some-query-token"))
+		})
+	})
+})
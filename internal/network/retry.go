@@ -0,0 +1,184 @@
+package network
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultInitialBackoff is the delay before the first retry of a request
+	// when a RetryPolicy does not specify one.
+	DefaultInitialBackoff = 100 * time.Millisecond
+
+	// DefaultMaxBackoff is the longest delay between attempts of a request
+	// when a RetryPolicy does not specify one.
+	DefaultMaxBackoff = 10 * time.Second
+)
+
+// DefaultRetryableStatusCodes are the response status codes that cause a
+// request to be retried when a RetryPolicy does not specify any.
+var DefaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryableMethods are the HTTP methods of the requests that may be
+// retried when a RetryPolicy does not specify any. Only idempotent methods
+// are included.
+var DefaultRetryableMethods = []string{"GET", "HEAD", "OPTIONS", "PUT", "DELETE"}
+
+// RetryPolicy describes how a Client retries requests that fail with a
+// transient error. The zero value makes a single attempt of every request.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is attempted,
+	// including the first attempt. Requests are not retried when this value
+	// is less than 2.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. The delay doubles
+	// for each subsequent retry, and is reduced by a random amount of up to
+	// half to spread out the retries of concurrent requests.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the longest delay between attempts. A request is not
+	// retried when the server asks for a longer delay using the Retry-After
+	// header.
+	MaxBackoff time.Duration
+
+	// RetryableStatusCodes are the response status codes that cause a request
+	// to be retried. This value defaults to DefaultRetryableStatusCodes.
+	RetryableStatusCodes []int
+
+	// RetryableMethods are the HTTP methods of the requests that may be
+	// retried. This value defaults to DefaultRetryableMethods. Non-idempotent
+	// methods, such as POST, must be included explicitly, as retrying them may
+	// repeat their effects.
+	RetryableMethods []string
+}
+
+func (p RetryPolicy) allowsMethod(method string) bool {
+	methods := p.RetryableMethods
+	if methods == nil {
+		methods = DefaultRetryableMethods
+	}
+
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p RetryPolicy) allowsStatus(code int) bool {
+	codes := p.RetryableStatusCodes
+	if codes == nil {
+		codes = DefaultRetryableStatusCodes
+	}
+
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff > 0 {
+		return p.MaxBackoff
+	}
+
+	return DefaultMaxBackoff
+}
+
+// backoff returns the jittered delay before the given retry, numbered from 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	if backoff <= 0 {
+		backoff = DefaultInitialBackoff
+	}
+
+	for i := 1; i < retry && backoff < p.maxBackoff(); i++ {
+		backoff *= 2
+	}
+
+	if backoff > p.maxBackoff() {
+		backoff = p.maxBackoff()
+	}
+
+	half := int64(backoff / 2)
+	if half == 0 {
+		return backoff
+	}
+
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// retryDelay reports whether a request that has been attempted the given
+// number of times should be retried after the outcome of its last attempt,
+// and how long to wait before doing so.
+func (p RetryPolicy) retryDelay(ctx context.Context, method string, attempts int, resp Response, err error) (time.Duration, bool) {
+	if attempts >= p.MaxAttempts || !p.allowsMethod(method) || ctx.Err() != nil {
+		return 0, false
+	}
+
+	if err != nil {
+		_, ok := err.(RequestHTTPError)
+		return p.backoff(attempts), ok
+	}
+
+	if !p.allowsStatus(resp.Code) {
+		return 0, false
+	}
+
+	if delay, ok := retryAfter(resp.Headers.Get("Retry-After")); ok {
+		return delay, delay <= p.maxBackoff()
+	}
+
+	return p.backoff(attempts), true
+}
+
+// retryAfter parses the value of a Retry-After header, given either as a
+// number of seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// wait blocks for the given delay, returning early with an error when the
+// context is done.
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,215 @@
+package network_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/network"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("retrying requests", func() {
+	var (
+		server   *httptest.Server
+		attempts int32
+		statuses []int
+		header   http.Header
+		policy   network.RetryPolicy
+	)
+
+	BeforeEach(func() {
+		attempts = 0
+		statuses = []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}
+		header = http.Header{}
+		policy = network.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     10 * time.Millisecond,
+		}
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			attempt := int(atomic.AddInt32(&attempts, 1))
+			if attempt > len(statuses) {
+				attempt = len(statuses)
+			}
+
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(statuses[attempt-1])
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	makeRequest := func(method string) (network.Response, error) {
+		return network.NewClient(network.Config{
+			Host:        server.URL,
+			TraceWriter: TraceWriter,
+			Retry:       policy,
+		}).MakeRequest(network.Request{
+			Method:                method,
+			Path:                  "/path",
+			AcceptableStatusCodes: []int{http.StatusOK},
+		})
+	}
+
+	It("retries requests that receive a retryable status", func() {
+		resp, err := makeRequest("GET")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Code).To(Equal(http.StatusOK))
+		Expect(attempts).To(Equal(int32(3)))
+	})
+
+	It("returns the last error once the attempts are exhausted", func() {
+		policy.MaxAttempts = 2
+
+		_, err := makeRequest("GET")
+		Expect(err).To(Equal(network.UnexpectedStatusError{Status: http.StatusBadGateway, Body: []byte{}}))
+		Expect(attempts).To(Equal(int32(2)))
+	})
+
+	It("makes a single attempt when no policy is configured", func() {
+		policy = network.RetryPolicy{}
+
+		_, err := makeRequest("GET")
+		Expect(err).To(BeAssignableToTypeOf(network.UnexpectedStatusError{}))
+		Expect(attempts).To(Equal(int32(1)))
+	})
+
+	It("does not retry requests that receive other statuses", func() {
+		statuses = []int{http.StatusInternalServerError, http.StatusOK}
+
+		_, err := makeRequest("GET")
+		Expect(err).To(BeAssignableToTypeOf(network.UnexpectedStatusError{}))
+		Expect(attempts).To(Equal(int32(1)))
+	})
+
+	It("retries the configured statuses", func() {
+		statuses = []int{http.StatusInternalServerError, http.StatusOK}
+		policy.RetryableStatusCodes = []int{http.StatusInternalServerError}
+
+		_, err := makeRequest("GET")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attempts).To(Equal(int32(2)))
+	})
+
+	It("does not retry POST requests by default", func() {
+		_, err := makeRequest("POST")
+		Expect(err).To(BeAssignableToTypeOf(network.UnexpectedStatusError{}))
+		Expect(attempts).To(Equal(int32(1)))
+	})
+
+	It("retries POST requests when they are explicitly allowed", func() {
+		policy.RetryableMethods = []string{"POST"}
+
+		_, err := makeRequest("POST")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attempts).To(Equal(int32(3)))
+	})
+
+	It("retries requests that fail to connect", func() {
+		var failures int32
+		flakyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if atomic.AddInt32(&failures, 1) == 1 {
+				conn, _, err := w.(http.Hijacker).Hijack()
+				Expect(err).NotTo(HaveOccurred())
+				conn.Close()
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer flakyServer.Close()
+
+		_, err := network.NewClient(network.Config{
+			Host:        flakyServer.URL,
+			TraceWriter: TraceWriter,
+			Retry:       policy,
+		}).MakeRequest(network.Request{
+			Method:                "GET",
+			Path:                  "/path",
+			AcceptableStatusCodes: []int{http.StatusOK},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failures).To(Equal(int32(2)))
+	})
+
+	Context("when the response includes a Retry-After header", func() {
+		BeforeEach(func() {
+			statuses = []int{http.StatusTooManyRequests, http.StatusOK}
+		})
+
+		It("waits for the requested delay", func() {
+			header.Set("Retry-After", "1")
+			policy.MaxBackoff = 2 * time.Second
+
+			start := time.Now()
+			_, err := makeRequest("GET")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attempts).To(Equal(int32(2)))
+			Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+		})
+
+		It("does not retry when the requested delay is longer than the maximum backoff", func() {
+			header.Set("Retry-After", "120")
+
+			_, err := makeRequest("GET")
+			Expect(err).To(BeAssignableToTypeOf(network.UnexpectedStatusError{}))
+			Expect(attempts).To(Equal(int32(1)))
+		})
+
+		It("accepts the delay as an HTTP date", func() {
+			header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+
+			_, err := makeRequest("GET")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attempts).To(Equal(int32(2)))
+		})
+	})
+
+	It("stops retrying when the context is done", func() {
+		policy.InitialBackoff = time.Minute
+		policy.MaxBackoff = time.Minute
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := network.NewClient(network.Config{
+			Host:        server.URL,
+			TraceWriter: TraceWriter,
+			Retry:       policy,
+		}).MakeRequest(network.Request{
+			Method:                "GET",
+			Path:                  "/path",
+			AcceptableStatusCodes: []int{http.StatusOK},
+			Context:               ctx,
+		})
+		Expect(err).To(BeAssignableToTypeOf(network.RequestHTTPError{}))
+		Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+		Expect(attempts).To(Equal(int32(1)))
+	})
+
+	Describe("backoff", func() {
+		It("doubles for each retry with up to half as jitter, up to the maximum", func() {
+			policy = network.RetryPolicy{
+				InitialBackoff: 100 * time.Millisecond,
+				MaxBackoff:     time.Second,
+			}
+
+			for i := 0; i < 100; i++ {
+				Expect(policy.Backoff(1)).To(BeNumerically("~", 75*time.Millisecond, 25*time.Millisecond))
+				Expect(policy.Backoff(2)).To(BeNumerically("~", 150*time.Millisecond, 50*time.Millisecond))
+				Expect(policy.Backoff(3)).To(BeNumerically("~", 300*time.Millisecond, 100*time.Millisecond))
+				Expect(policy.Backoff(10)).To(BeNumerically("~", 750*time.Millisecond, 250*time.Millisecond))
+			}
+		})
+	})
+})
@@ -1,9 +1,16 @@
 package network
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
+	"os"
+	"sync"
 	"time"
 )
 
@@ -12,28 +19,239 @@ The purpose of the transports defined herein is to stop a program that
 consumes this package from using up all of the file descriptors provided
 by the operating system. The implementation here ensures that the HTTP
 client for this library will consume, at most, 2 file descriptors, one
-for each transport.
+for each transport, when the default TLS settings are used. Clients that
+configure CA certificates, a client certificate, or a minimum TLS version
+share a single transport for each distinct combination of those settings,
+up to maxTLSTransports, after which the least recently built transport is
+evicted. Certificate files are checked for changes to their size or
+modification time on every request, and the transport for their paths is
+rebuilt when they change, so that rotated certificates are picked up
+without a restart.
 */
 
-var _transports map[bool]http.RoundTripper
+const maxTLSTransports = 32
+
+var (
+	_transports         map[bool]http.RoundTripper
+	_tlsTransports      map[transportKey]tlsTransport
+	_tlsTransportOrder  []transportKey
+	_tlsTransportsMutex sync.Mutex
+)
 
 func init() {
 	_transports = map[bool]http.RoundTripper{
-		true:  _buildTransport(true),
-		false: _buildTransport(false),
+		true:  _buildTransport(&tls.Config{InsecureSkipVerify: true}),
+		false: _buildTransport(&tls.Config{InsecureSkipVerify: false}),
 	}
+	_tlsTransports = map[transportKey]tlsTransport{}
+}
+
+// transportKey identifies the TLS settings of a Config. Certificates and
+// keys given inline are held as digests, so that the cache does not hold
+// on to the key material.
+type transportKey struct {
+	skipVerifySSL  bool
+	caCerts        [sha256.Size]byte
+	caCertsFile    string
+	clientCert     [sha256.Size]byte
+	clientKey      [sha256.Size]byte
+	clientCertFile string
+	clientKeyFile  string
+	minTLSVersion  uint16
+}
+
+// fileState records the size and modification time of a certificate file.
+type fileState struct {
+	size    int64
+	modTime int64
+}
+
+// tlsTransport is a cached transport, along with the state of the
+// certificate files from which it was built.
+type tlsTransport struct {
+	files     [3]fileState
+	transport http.RoundTripper
+}
+
+func newTransportKey(config Config) transportKey {
+	return transportKey{
+		skipVerifySSL:  config.SkipVerifySSL,
+		caCerts:        digest(config.CACerts),
+		caCertsFile:    config.CACertsFile,
+		clientCert:     digest(config.ClientCert),
+		clientKey:      digest(config.ClientKey),
+		clientCertFile: config.ClientCertFile,
+		clientKeyFile:  config.ClientKeyFile,
+		minTLSVersion:  config.MinTLSVersion,
+	}
+}
+
+// digest returns the SHA-256 digest of the given contents, or the zero
+// value when there are none.
+func digest(contents []byte) [sha256.Size]byte {
+	if len(contents) == 0 {
+		return [sha256.Size]byte{}
+	}
+
+	return sha256.Sum256(contents)
 }
 
 func buildTransport(skipVerifySSL bool) http.RoundTripper {
 	return _transports[skipVerifySSL]
 }
 
-func _buildTransport(skipVerifySSL bool) http.RoundTripper {
+// transportFor returns the transport shared by all clients with the TLS
+// settings of the given Config. Any certificate files are checked on every
+// call, and are read again when their size or modification time changes.
+func transportFor(config Config) (http.RoundTripper, error) {
+	key := newTransportKey(config)
+	if key == (transportKey{skipVerifySSL: key.skipVerifySSL}) {
+		return buildTransport(key.skipVerifySSL), nil
+	}
+
+	files, err := statFiles(config)
+	if err != nil {
+		return nil, err
+	}
+
+	_tlsTransportsMutex.Lock()
+	defer _tlsTransportsMutex.Unlock()
+
+	if cached, ok := _tlsTransports[key]; ok {
+		if cached.files == files {
+			return cached.transport, nil
+		}
+
+		evictTransport(key)
+	}
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(_tlsTransportOrder) >= maxTLSTransports {
+		evictTransport(_tlsTransportOrder[0])
+	}
+
+	transport := _buildTransport(tlsConfig)
+	_tlsTransports[key] = tlsTransport{
+		files:     files,
+		transport: transport,
+	}
+	_tlsTransportOrder = append(_tlsTransportOrder, key)
+
+	return transport, nil
+}
+
+// evictTransport removes the transport with the given key from the cache,
+// closing its idle connections. It must be called with the mutex held.
+func evictTransport(key transportKey) {
+	if transport, ok := _tlsTransports[key].transport.(*http.Transport); ok {
+		transport.CloseIdleConnections()
+	}
+	delete(_tlsTransports, key)
+
+	for i, k := range _tlsTransportOrder {
+		if k == key {
+			_tlsTransportOrder = append(_tlsTransportOrder[:i], _tlsTransportOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+func statFiles(config Config) ([3]fileState, error) {
+	var files [3]fileState
+	for i, file := range []struct {
+		path        string
+		description string
+	}{
+		{config.CACertsFile, "CA certificates file"},
+		{config.ClientCertFile, "client certificate file"},
+		{config.ClientKeyFile, "client key file"},
+	} {
+		if file.path == "" {
+			continue
+		}
+
+		info, err := os.Stat(file.path)
+		if err != nil {
+			return files, fmt.Errorf("could not read %s: %s", file.description, err)
+		}
+
+		files[i] = fileState{
+			size:    info.Size(),
+			modTime: info.ModTime().UnixNano(),
+		}
+	}
+
+	return files, nil
+}
+
+func newTLSConfig(config Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.SkipVerifySSL,
+		MinVersion:         config.MinTLSVersion,
+	}
+
+	if len(config.CACerts) > 0 || config.CACertsFile != "" {
+		pool := x509.NewCertPool()
+
+		if len(config.CACerts) > 0 && !pool.AppendCertsFromPEM(config.CACerts) {
+			return nil, errors.New("CA certificates do not contain any PEM encoded certificates")
+		}
+
+		if config.CACertsFile != "" {
+			caCerts, err := ioutil.ReadFile(config.CACertsFile)
+			if err != nil {
+				return nil, fmt.Errorf("could not read CA certificates file: %s", err)
+			}
+
+			if !pool.AppendCertsFromPEM(caCerts) {
+				return nil, fmt.Errorf("CA certificates file %s does not contain any PEM encoded certificates", config.CACertsFile)
+			}
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	clientCert, clientKey := config.ClientCert, config.ClientKey
+	if config.ClientCertFile != "" {
+		var err error
+		clientCert, err = ioutil.ReadFile(config.ClientCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read client certificate file: %s", err)
+		}
+	}
+
+	if config.ClientKeyFile != "" {
+		var err error
+		clientKey, err = ioutil.ReadFile(config.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read client key file: %s", err)
+		}
+	}
+
+	if len(clientCert) > 0 || len(clientKey) > 0 {
+		if len(clientCert) == 0 || len(clientKey) == 0 {
+			return nil, errors.New("client certificate and client key must be given together")
+		}
+
+		certificate, err := tls.X509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %s", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+func _buildTransport(tlsConfig *tls.Config) http.RoundTripper {
 	return &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: skipVerifySSL,
-		},
-		Proxy: http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+		Proxy:           http.ProxyFromEnvironment,
 		Dial: (&net.Dialer{
 			Timeout:   30 * time.Second,
 			KeepAlive: 30 * time.Second,
@@ -1,7 +1,20 @@
 package network_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"time"
 
@@ -37,4 +50,240 @@ var _ = Describe("using a global HTTP client", func() {
 		Expect(reflect.ValueOf(transport.Proxy).Pointer()).To(Equal(reflect.ValueOf(http.ProxyFromEnvironment).Pointer()))
 		Expect(transport.TLSHandshakeTimeout).To(Equal(10 * time.Second))
 	})
+
+	Context("when TLS settings are configured", func() {
+		var (
+			server     *httptest.Server
+			caCerts    []byte
+			clientCert []byte
+			clientKey  []byte
+			tempDir    string
+		)
+
+		BeforeEach(func() {
+			clientCert, clientKey = generateCertificate()
+
+			clientCAs := x509.NewCertPool()
+			Expect(clientCAs.AppendCertsFromPEM(clientCert)).To(BeTrue())
+
+			server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+			server.TLS = &tls.Config{
+				ClientAuth: tls.RequireAndVerifyClientCert,
+				ClientCAs:  clientCAs,
+				MaxVersion: tls.VersionTLS12,
+			}
+			server.StartTLS()
+
+			caCerts = pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE",
+				Bytes: server.Certificate().Raw,
+			})
+
+			var err error
+			tempDir, err = ioutil.TempDir("", "warrant-tls")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+			Expect(os.RemoveAll(tempDir)).To(Succeed())
+		})
+
+		makeRequest := func(config network.Config) error {
+			config.Host = server.URL
+			config.TraceWriter = TraceWriter
+
+			_, err := network.NewClient(config).MakeRequest(network.Request{
+				Method:                "GET",
+				Path:                  "/",
+				AcceptableStatusCodes: []int{http.StatusOK},
+			})
+			return err
+		}
+
+		writeFile := func(name string, contents []byte) string {
+			path := filepath.Join(tempDir, name)
+			Expect(ioutil.WriteFile(path, contents, 0600)).To(Succeed())
+			return path
+		}
+
+		It("retrieves the exact same client reference for the same settings", func() {
+			transport1, err := network.TransportFor(network.Config{CACerts: caCerts})
+			Expect(err).NotTo(HaveOccurred())
+
+			transport2, err := network.TransportFor(network.Config{CACerts: caCerts})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(reflect.ValueOf(transport1).Pointer()).To(Equal(reflect.ValueOf(transport2).Pointer()))
+		})
+
+		It("retrieves different client references for different settings", func() {
+			transport1, err := network.TransportFor(network.Config{CACerts: caCerts})
+			Expect(err).NotTo(HaveOccurred())
+
+			transport2, err := network.TransportFor(network.Config{CACerts: caCerts, MinTLSVersion: tls.VersionTLS12})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(reflect.ValueOf(transport1).Pointer()).NotTo(Equal(reflect.ValueOf(transport2).Pointer()))
+			Expect(transport2.(*http.Transport).TLSClientConfig.MinVersion).To(Equal(uint16(tls.VersionTLS12)))
+		})
+
+		It("evicts the oldest transport when too many settings are in use", func() {
+			first, err := network.TransportFor(network.Config{CACerts: caCerts, MinTLSVersion: 1})
+			Expect(err).NotTo(HaveOccurred())
+
+			for version := 2; version <= network.MaxTLSTransports+1; version++ {
+				_, err := network.TransportFor(network.Config{CACerts: caCerts, MinTLSVersion: uint16(version)})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(network.TLSTransportCount()).To(Equal(network.MaxTLSTransports))
+
+			again, err := network.TransportFor(network.Config{CACerts: caCerts, MinTLSVersion: 1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reflect.ValueOf(again).Pointer()).NotTo(Equal(reflect.ValueOf(first).Pointer()))
+		})
+
+		It("uses the shared transports when only SkipVerifySSL is set", func() {
+			transport, err := network.TransportFor(network.Config{SkipVerifySSL: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(reflect.ValueOf(transport).Pointer()).To(Equal(reflect.ValueOf(network.BuildTransport(true)).Pointer()))
+		})
+
+		It("trusts the given CA certificates and presents the client certificate", func() {
+			err := makeRequest(network.Config{
+				CACerts:    caCerts,
+				ClientCert: clientCert,
+				ClientKey:  clientKey,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reads the CA certificates and client certificate from files", func() {
+			err := makeRequest(network.Config{
+				CACertsFile:    writeFile("ca.pem", caCerts),
+				ClientCertFile: writeFile("cert.pem", clientCert),
+				ClientKeyFile:  writeFile("key.pem", clientKey),
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("picks up certificate files that have changed", func() {
+			otherCert, _ := generateCertificate()
+
+			config := network.Config{
+				CACertsFile: writeFile("ca.pem", otherCert),
+				ClientCert:  clientCert,
+				ClientKey:   clientKey,
+			}
+
+			transport1, err := network.TransportFor(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = makeRequest(config)
+			Expect(err).To(MatchError(ContainSubstring("certificate signed by unknown authority")))
+
+			count := network.TLSTransportCount()
+
+			path := writeFile("ca.pem", caCerts)
+			modTime := time.Now().Add(time.Minute)
+			Expect(os.Chtimes(path, modTime, modTime)).To(Succeed())
+
+			transport2, err := network.TransportFor(config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reflect.ValueOf(transport1).Pointer()).NotTo(Equal(reflect.ValueOf(transport2).Pointer()))
+			Expect(network.TLSTransportCount()).To(Equal(count))
+
+			err = makeRequest(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			transport3, err := network.TransportFor(config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reflect.ValueOf(transport2).Pointer()).To(Equal(reflect.ValueOf(transport3).Pointer()))
+		})
+
+		Context("failure cases", func() {
+			It("does not trust servers signed by other authorities", func() {
+				otherCert, _ := generateCertificate()
+
+				err := makeRequest(network.Config{
+					CACerts:    otherCert,
+					ClientCert: clientCert,
+					ClientKey:  clientKey,
+				})
+				Expect(err).To(BeAssignableToTypeOf(network.RequestHTTPError{}))
+				Expect(err).To(MatchError(ContainSubstring("certificate signed by unknown authority")))
+			})
+
+			It("is rejected by servers requiring a client certificate when none is given", func() {
+				err := makeRequest(network.Config{CACerts: caCerts})
+				Expect(err).To(BeAssignableToTypeOf(network.RequestHTTPError{}))
+			})
+
+			It("does not negotiate versions of TLS below the minimum", func() {
+				err := makeRequest(network.Config{
+					CACerts:       caCerts,
+					ClientCert:    clientCert,
+					ClientKey:     clientKey,
+					MinTLSVersion: tls.VersionTLS13,
+				})
+				Expect(err).To(BeAssignableToTypeOf(network.RequestHTTPError{}))
+				Expect(err).To(MatchError(ContainSubstring("protocol version")))
+			})
+
+			It("returns a RequestConfigurationError when the CA certificates are not PEM encoded", func() {
+				err := makeRequest(network.Config{CACerts: []byte("not a certificate")})
+				Expect(err).To(BeAssignableToTypeOf(network.RequestConfigurationError{}))
+				Expect(err).To(MatchError("Warrant RequestConfigurationError: CA certificates do not contain any PEM encoded certificates"))
+			})
+
+			It("returns a RequestConfigurationError when the CA certificates file cannot be read", func() {
+				err := makeRequest(network.Config{CACertsFile: filepath.Join(tempDir, "missing.pem")})
+				Expect(err).To(BeAssignableToTypeOf(network.RequestConfigurationError{}))
+				Expect(err).To(MatchError(ContainSubstring("could not read CA certificates file")))
+			})
+
+			It("returns a RequestConfigurationError when the client key is missing", func() {
+				err := makeRequest(network.Config{ClientCert: clientCert})
+				Expect(err).To(BeAssignableToTypeOf(network.RequestConfigurationError{}))
+				Expect(err).To(MatchError("Warrant RequestConfigurationError: client certificate and client key must be given together"))
+			})
+
+			It("returns a RequestConfigurationError when the client key does not match the certificate", func() {
+				_, otherKey := generateCertificate()
+
+				err := makeRequest(network.Config{ClientCert: clientCert, ClientKey: otherKey})
+				Expect(err).To(BeAssignableToTypeOf(network.RequestConfigurationError{}))
+				Expect(err).To(MatchError(ContainSubstring("could not load client certificate")))
+			})
+		})
+	})
 })
+
+func generateCertificate() (certificate, key []byte) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "warrant-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	Expect(err).NotTo(HaveOccurred())
+
+	keyDER, err := x509.MarshalECPrivateKey(privateKey)
+	Expect(err).NotTo(HaveOccurred())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
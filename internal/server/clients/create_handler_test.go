@@ -108,10 +108,10 @@ var _ = Describe("createHandler", func() {
 		request.Header.Set("Authorization", fmt.Sprintf("bearer %s", authorization))
 
 		router.ServeHTTP(recorder, request)
-		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"error": "unauthorized",
-			"error_description":"Full authentication is required to access this resource"
+			"error": "insufficient_scope",
+			"error_description":"Insufficient scope for this resource"
 		}`))
 	})
 
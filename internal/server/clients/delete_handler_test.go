@@ -71,8 +71,8 @@ var _ = Describe("deleteHandler", func() {
 		router.ServeHTTP(recorder, request)
 		Expect(recorder.Code).To(Equal(http.StatusForbidden))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"error": "access_denied",
-			"error_description": "Invalid token does not contain resource id (clients)"
+			"error": "insufficient_scope",
+			"error_description": "Insufficient scope for this resource"
 		}`))
 	})
 })
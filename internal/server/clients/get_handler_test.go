@@ -97,10 +97,10 @@ var _ = Describe("getHandler", func() {
 		request.Header.Set("Authorization", fmt.Sprintf("bearer %s", token))
 
 		router.ServeHTTP(recorder, request)
-		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"error_description": "Full authentication is required to access this resource",
-			"error": "unauthorized"
+			"error_description": "Insufficient scope for this resource",
+			"error": "insufficient_scope"
 		}`))
 	})
 })
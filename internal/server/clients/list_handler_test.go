@@ -1,10 +1,12 @@
 package clients_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 
+	"github.com/pivotal-cf-experimental/warrant/internal/documents"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/clients"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
 
@@ -61,8 +63,8 @@ var _ = Describe("listHandler", func() {
 					"client_id": "admin",
 					"name": "admin",
 					"scope": [],
-					"resource_ids": ["clients", "password", "scim"],
-					"authorities": ["clients.read", "clients.write", "clients.secret", "password.write", "uaa.admin", "scim.read", "scim.write"],
+					"resource_ids": ["password", "scim"],
+					"authorities": ["clients.read", "clients.write", "clients.secret", "password.write", "uaa.admin", "uaa.resource", "scim.read", "scim.write"],
 					"authorized_grant_types": ["client_credentials"],
 					"autoapprove": [],
 					"access_token_validity": 3600,
@@ -104,10 +106,36 @@ var _ = Describe("listHandler", func() {
 		request.Header.Set("Authorization", fmt.Sprintf("bearer %s", token))
 
 		router.ServeHTTP(recorder, request)
-		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"error_description": "Full authentication is required to access this resource",
-			"error": "unauthorized"
+			"error_description": "Insufficient scope for this resource",
+			"error": "insufficient_scope"
+		}`))
+	})
+
+	It("returns the page of clients requested by startIndex and count", func() {
+		request.URL.RawQuery = "startIndex=2&count=1"
+
+		router.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var document documents.ClientListResponse
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &document)).To(Succeed())
+		Expect(document.Resources).To(HaveLen(1))
+		Expect(document.Resources[0].ClientID).To(Equal("some-client-id"))
+		Expect(document.StartIndex).To(Equal(2))
+		Expect(document.ItemsPerPage).To(Equal(1))
+		Expect(document.TotalResults).To(Equal(2))
+	})
+
+	It("returns a 400 when the pagination parameters are invalid", func() {
+		request.URL.RawQuery = "count=banana"
+
+		router.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"error_description": "Invalid count: [banana]",
+			"error": "scim"
 		}`))
 	})
 })
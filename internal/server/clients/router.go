@@ -2,17 +2,28 @@ package clients
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
 )
 
+var (
+	readPolicy = common.Policy{
+		Scopes: []string{"clients.read", "clients.admin"},
+	}
+
+	writePolicy = common.Policy{
+		Scopes: []string{"clients.write", "clients.admin"},
+	}
+)
+
 func NewRouter(clients *domain.Clients, tokens *domain.Tokens) *mux.Router {
 	router := mux.NewRouter()
 
-	router.Handle("/oauth/clients", createHandler{clients, tokens}).Methods("POST")
-	router.Handle("/oauth/clients", listHandler{clients, tokens}).Methods("GET")
-	router.Handle("/oauth/clients/{guid}", getHandler{clients, tokens}).Methods("GET")
-	router.Handle("/oauth/clients/{guid}", updateHandler{clients, tokens}).Methods("PUT")
-	router.Handle("/oauth/clients/{guid}", deleteHandler{clients, tokens}).Methods("DELETE")
+	router.Handle("/oauth/clients", common.Authorize(tokens, writePolicy, createHandler{clients})).Methods("POST")
+	router.Handle("/oauth/clients", common.Authorize(tokens, readPolicy, listHandler{clients})).Methods("GET")
+	router.Handle("/oauth/clients/{guid}", common.Authorize(tokens, readPolicy, getHandler{clients})).Methods("GET")
+	router.Handle("/oauth/clients/{guid}", common.Authorize(tokens, writePolicy, updateHandler{clients})).Methods("PUT")
+	router.Handle("/oauth/clients/{guid}", common.Authorize(tokens, writePolicy, deleteHandler{clients})).Methods("DELETE")
 
 	return router
 }
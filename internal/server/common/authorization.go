@@ -0,0 +1,63 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type Policy struct {
+	Audiences []string
+	Scopes    []string
+}
+
+type Authorizer interface {
+	Authorize(token string, policy Policy) error
+}
+
+type AccessDeniedError struct {
+	Audience string
+}
+
+func (e AccessDeniedError) Error() string {
+	return fmt.Sprintf("Invalid token does not contain resource id (%s)", e.Audience)
+}
+
+type InsufficientScopeError struct{}
+
+func (e InsufficientScopeError) Error() string {
+	return "Insufficient scope for this resource"
+}
+
+// Authorize responds with a 401 when the request has no valid token, and with
+// a 403 when the token lacks a required audience or scope, matching UAA and
+// the warrant.ResourceServer.
+func Authorize(authorizer Authorizer, policy Policy, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token := BearerToken(req)
+		if len(token) == 0 {
+			JSONError(w, http.StatusUnauthorized, "Full authentication is required to access this resource", "unauthorized")
+			return
+		}
+
+		switch err := authorizer.Authorize(token, policy).(type) {
+		case nil:
+			handler.ServeHTTP(w, req)
+		case AccessDeniedError:
+			JSONError(w, http.StatusForbidden, err.Error(), "access_denied")
+		case InsufficientScopeError:
+			JSONError(w, http.StatusForbidden, err.Error(), "insufficient_scope")
+		default:
+			JSONError(w, http.StatusUnauthorized, err.Error(), "invalid_token")
+		}
+	})
+}
+
+func BearerToken(req *http.Request) string {
+	header := req.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return header[7:]
+	}
+
+	return header
+}
@@ -0,0 +1,446 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type Filterable interface {
+	FilterValues(attribute string) ([]string, bool)
+}
+
+type Filter interface {
+	Matches(resource Filterable) bool
+	Validate(resource Filterable) error
+}
+
+func ParseFilter(expression string) (Filter, error) {
+	if strings.TrimSpace(expression) == "" {
+		return matchAll{}, nil
+	}
+
+	tokens, err := tokenizeFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q", p.peek().value)
+	}
+
+	return filter, nil
+}
+
+type matchAll struct{}
+
+func (matchAll) Matches(Filterable) bool   { return true }
+func (matchAll) Validate(Filterable) error { return nil }
+
+type logicalFilter struct {
+	operator string
+	left     Filter
+	right    Filter
+}
+
+func (f logicalFilter) Matches(resource Filterable) bool {
+	if f.operator == "and" {
+		return f.left.Matches(resource) && f.right.Matches(resource)
+	}
+
+	return f.left.Matches(resource) || f.right.Matches(resource)
+}
+
+func (f logicalFilter) Validate(resource Filterable) error {
+	if err := f.left.Validate(resource); err != nil {
+		return err
+	}
+
+	return f.right.Validate(resource)
+}
+
+type notFilter struct {
+	filter Filter
+}
+
+func (f notFilter) Matches(resource Filterable) bool {
+	return !f.filter.Matches(resource)
+}
+
+func (f notFilter) Validate(resource Filterable) error {
+	return f.filter.Validate(resource)
+}
+
+type attributeFilter struct {
+	attribute string
+	operator  string
+	value     string
+	isNull    bool
+}
+
+func (f attributeFilter) Validate(resource Filterable) error {
+	if _, ok := resource.FilterValues(f.attribute); !ok {
+		return fmt.Errorf("unknown attribute %q", f.attribute)
+	}
+
+	return nil
+}
+
+func (f attributeFilter) Matches(resource Filterable) bool {
+	values, _ := resource.FilterValues(f.attribute)
+
+	if f.operator == "pr" {
+		return present(values)
+	}
+
+	if f.isNull {
+		switch f.operator {
+		case "eq":
+			return !present(values)
+		case "ne":
+			return present(values)
+		default:
+			return false
+		}
+	}
+
+	if f.operator == "ne" {
+		for _, value := range values {
+			if compareFilterValues(value, f.value) == 0 {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for _, value := range values {
+		if f.match(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f attributeFilter) match(value string) bool {
+	switch f.operator {
+	case "eq":
+		return compareFilterValues(value, f.value) == 0
+	case "co":
+		return strings.Contains(strings.ToLower(value), strings.ToLower(f.value))
+	case "sw":
+		return strings.HasPrefix(strings.ToLower(value), strings.ToLower(f.value))
+	case "ew":
+		return strings.HasSuffix(strings.ToLower(value), strings.ToLower(f.value))
+	case "gt":
+		return compareFilterValues(value, f.value) > 0
+	case "ge":
+		return compareFilterValues(value, f.value) >= 0
+	case "lt":
+		return compareFilterValues(value, f.value) < 0
+	case "le":
+		return compareFilterValues(value, f.value) <= 0
+	default:
+		return false
+	}
+}
+
+func present(values []string) bool {
+	for _, value := range values {
+		if value != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func compareFilterValues(a, b string) int {
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	if x, err := time.Parse(time.RFC3339Nano, a); err == nil {
+		if y, err := time.Parse(time.RFC3339Nano, b); err == nil {
+			switch {
+			case x.Before(y):
+				return -1
+			case x.After(y):
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+var filterOperators = map[string]bool{
+	"eq": true,
+	"ne": true,
+	"co": true,
+	"sw": true,
+	"ew": true,
+	"gt": true,
+	"ge": true,
+	"lt": true,
+	"le": true,
+	"pr": true,
+}
+
+type filterTokenKind int
+
+const (
+	filterWord filterTokenKind = iota
+	filterString
+	filterOpenParen
+	filterCloseParen
+)
+
+type filterToken struct {
+	kind  filterTokenKind
+	value string
+}
+
+func tokenizeFilter(expression string) ([]filterToken, error) {
+	var tokens []filterToken
+
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: filterOpenParen, value: "("})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: filterCloseParen, value: ")"})
+			i++
+
+		case r == '"' || r == '\'':
+			quote := r
+			var value []rune
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string")
+				}
+
+				if runes[i] == '\\' && i+1 < len(runes) {
+					value = append(value, runes[i+1])
+					i += 2
+					continue
+				}
+
+				if runes[i] == quote {
+					i++
+					break
+				}
+
+				value = append(value, runes[i])
+				i++
+			}
+
+			tokens = append(tokens, filterToken{kind: filterString, value: string(value)})
+
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != '"' && runes[i] != '\'' {
+				i++
+			}
+
+			tokens = append(tokens, filterToken{kind: filterWord, value: string(runes[start:i])})
+		}
+	}
+
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens   []filterToken
+	position int
+}
+
+func (p *filterParser) done() bool {
+	return p.position >= len(p.tokens)
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.position]
+}
+
+func (p *filterParser) next() (filterToken, error) {
+	if p.done() {
+		return filterToken{}, fmt.Errorf("unexpected end of expression")
+	}
+
+	token := p.tokens[p.position]
+	p.position++
+	return token, nil
+}
+
+func (p *filterParser) peekKeyword(keyword string) bool {
+	return !p.done() && p.peek().kind == filterWord && strings.ToLower(p.peek().value) == keyword
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peekKeyword("or") {
+		p.position++
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = logicalFilter{operator: "or", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peekKeyword("and") {
+		p.position++
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = logicalFilter{operator: "and", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (Filter, error) {
+	if p.peekKeyword("not") {
+		p.position++
+
+		if p.done() || p.peek().kind != filterOpenParen {
+			return nil, fmt.Errorf("expected ( after not")
+		}
+
+		filter, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+
+		return notFilter{filter}, nil
+	}
+
+	if !p.done() && p.peek().kind == filterOpenParen {
+		return p.parseGroup()
+	}
+
+	return p.parseAttribute()
+}
+
+func (p *filterParser) parseGroup() (Filter, error) {
+	p.position++
+
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if token.kind != filterCloseParen {
+		return nil, fmt.Errorf("expected ) but found %q", token.value)
+	}
+
+	return filter, nil
+}
+
+func (p *filterParser) parseAttribute() (Filter, error) {
+	attribute, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if attribute.kind != filterWord {
+		return nil, fmt.Errorf("expected attribute but found %q", attribute.value)
+	}
+
+	operator, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	op := strings.ToLower(operator.value)
+	if operator.kind != filterWord || !filterOperators[op] {
+		return nil, fmt.Errorf("invalid operator %q", operator.value)
+	}
+
+	filter := attributeFilter{
+		attribute: attribute.value,
+		operator:  op,
+	}
+
+	if op == "pr" {
+		return filter, nil
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch value.kind {
+	case filterString:
+		filter.value = value.value
+	case filterWord:
+		switch literal := strings.ToLower(value.value); {
+		case literal == "null":
+			filter.isNull = true
+		case literal == "true" || literal == "false":
+			filter.value = literal
+		default:
+			if _, err := strconv.ParseFloat(value.value, 64); err != nil {
+				return nil, fmt.Errorf("invalid value %q", value.value)
+			}
+			filter.value = value.value
+		}
+	default:
+		return nil, fmt.Errorf("expected value but found %q", value.value)
+	}
+
+	return filter, nil
+}
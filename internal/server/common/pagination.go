@@ -0,0 +1,37 @@
+package common
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const DefaultCount = 100
+
+func Pagination(query url.Values) (startIndex, count int, err error) {
+	startIndex, count = 1, DefaultCount
+
+	if value := query.Get("startIndex"); value != "" {
+		startIndex, err = strconv.Atoi(value)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid startIndex: [%s]", value)
+		}
+
+		if startIndex < 1 {
+			startIndex = 1
+		}
+	}
+
+	if value := query.Get("count"); value != "" {
+		count, err = strconv.Atoi(value)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid count: [%s]", value)
+		}
+
+		if count < 1 {
+			count = DefaultCount
+		}
+	}
+
+	return startIndex, count, nil
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"sync"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
+)
+
+type AuthorizationCode struct {
+	ClientID            string
+	UserID              string
+	Scopes              []string
+	RedirectURI         string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+func (c AuthorizationCode) VerifyCodeChallenge(codeVerifier string) bool {
+	if c.CodeChallenge == "" {
+		return true
+	}
+
+	switch c.CodeChallengeMethod {
+	case "S256":
+		sum := sha256.Sum256([]byte(codeVerifier))
+		return base64.RawURLEncoding.EncodeToString(sum[:]) == c.CodeChallenge
+	case "", "plain":
+		return codeVerifier == c.CodeChallenge
+	default:
+		return false
+	}
+}
+
+type authorizationCodes struct {
+	mutex sync.Mutex
+	store map[string]AuthorizationCode
+}
+
+func newAuthorizationCodes() *authorizationCodes {
+	return &authorizationCodes{
+		store: map[string]AuthorizationCode{},
+	}
+}
+
+func (a *authorizationCodes) issue(code AuthorizationCode) string {
+	value, err := common.NewUUID()
+	if err != nil {
+		panic(err)
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.store[value] = code
+	return value
+}
+
+func (a *authorizationCodes) redeem(value string) (AuthorizationCode, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	code, ok := a.store[value]
+	delete(a.store, value)
+	return code, ok
+}
+
+func (a *authorizationCodes) clear() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.store = map[string]AuthorizationCode{}
+}
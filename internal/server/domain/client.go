@@ -79,3 +79,7 @@ func (c Client) Validate() error {
 
 	return nil
 }
+
+func (c Client) HasAuthority(authority string) bool {
+	return contains(c.Authorities, authority)
+}
@@ -1,12 +1,13 @@
 package domain
 
+import "sync"
+
 var ADMIN_CLIENT = Client{
 	ID:     "admin",
 	Name:   "admin",
 	Secret: "admin",
 	Scope:  []string{},
 	ResourceIDs: []string{
-		"clients", //TODO: This is not needed, but checked in some handlers incorrectly
 		"password",
 		"scim",
 	},
@@ -16,6 +17,7 @@ var ADMIN_CLIENT = Client{
 		"clients.secret",
 		"password.write",
 		"uaa.admin",
+		"uaa.resource",
 		"scim.read",
 		"scim.write",
 	},
@@ -28,18 +30,40 @@ var ADMIN_CLIENT = Client{
 }
 
 type Clients struct {
+	mutex sync.RWMutex
 	store map[string]Client
+	admin Client
 }
 
 func NewClients() *Clients {
 	return &Clients{
 		store: map[string]Client{
-			"admin": ADMIN_CLIENT,
+			ADMIN_CLIENT.ID: ADMIN_CLIENT,
 		},
+		admin: ADMIN_CLIENT,
 	}
 }
 
-func (collection Clients) All() []Client {
+func (collection *Clients) Admin() Client {
+	collection.mutex.RLock()
+	defer collection.mutex.RUnlock()
+
+	return collection.admin
+}
+
+func (collection *Clients) SetAdmin(c Client) {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
+	delete(collection.store, collection.admin.ID)
+	collection.store[c.ID] = c
+	collection.admin = c
+}
+
+func (collection *Clients) All() []Client {
+	collection.mutex.RLock()
+	defer collection.mutex.RUnlock()
+
 	var clients []Client
 	for _, c := range collection.store {
 		clients = append(clients, c)
@@ -47,22 +71,47 @@ func (collection Clients) All() []Client {
 	return clients
 }
 
-func (collection Clients) Add(c Client) {
+func (collection *Clients) Add(c Client) {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
 	collection.store[c.ID] = c
 }
 
-func (collection Clients) Get(id string) (Client, bool) {
+func (collection *Clients) Get(id string) (Client, bool) {
+	collection.mutex.RLock()
+	defer collection.mutex.RUnlock()
+
 	c, ok := collection.store[id]
 	return c, ok
 }
 
 func (collection *Clients) Clear() {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
 	collection.store = map[string]Client{
-		"admin": ADMIN_CLIENT,
+		collection.admin.ID: collection.admin,
 	}
 }
 
-func (collection Clients) Delete(id string) bool {
+func (collection *Clients) Replace(list []Client) {
+	store := make(map[string]Client)
+	for _, c := range list {
+		store[c.ID] = c
+	}
+
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
+	store[collection.admin.ID] = collection.admin
+	collection.store = store
+}
+
+func (collection *Clients) Delete(id string) bool {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
 	_, ok := collection.store[id]
 	delete(collection.store, id)
 	return ok
@@ -19,3 +19,14 @@ func (cl ClientsList) ToDocument() documents.ClientListResponse {
 
 	return doc
 }
+
+func (cl ClientsList) ToPageDocument(startIndex, count int) documents.ClientListResponse {
+	start, end := pageBounds(len(cl), startIndex, count)
+
+	doc := cl[start:end].ToDocument()
+	doc.StartIndex = startIndex
+	doc.ItemsPerPage = count
+	doc.TotalResults = len(cl)
+
+	return doc
+}
@@ -22,3 +22,16 @@ func shuffle(src []string) []string {
 	}
 	return final
 }
+
+func pageBounds(total, startIndex, count int) (int, int) {
+	start := startIndex - 1
+	if start > total {
+		start = total
+	}
+
+	if count > total-start {
+		count = total - start
+	}
+
+	return start, start + count
+}
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
+)
+
+var userFilterAttributes = map[string]func(User) []string{
+	"id":              func(u User) []string { return []string{u.ID} },
+	"externalid":      func(u User) []string { return []string{u.ExternalID} },
+	"username":        func(u User) []string { return []string{u.UserName} },
+	"name.formatted":  func(u User) []string { return []string{u.FormattedName} },
+	"name.familyname": func(u User) []string { return []string{u.FamilyName} },
+	"name.givenname":  func(u User) []string { return []string{u.GivenName} },
+	"name.middlename": func(u User) []string { return []string{u.MiddleName} },
+	"emails":          func(u User) []string { return u.Emails },
+	"emails.value":    func(u User) []string { return u.Emails },
+	"active":          func(u User) []string { return []string{strconv.FormatBool(u.Active)} },
+	"verified":        func(u User) []string { return []string{strconv.FormatBool(u.Verified)} },
+	"origin":          func(u User) []string { return []string{u.Origin} },
+	"meta.created":    func(u User) []string { return []string{u.CreatedAt.Format(time.RFC3339Nano)} },
+	"meta.lastmodified": func(u User) []string {
+		return []string{u.UpdatedAt.Format(time.RFC3339Nano)}
+	},
+}
+
+var groupFilterAttributes = map[string]func(group) []string{
+	"id":          func(g group) []string { return []string{g.ID} },
+	"displayname": func(g group) []string { return []string{g.DisplayName} },
+	"description": func(g group) []string { return []string{g.Description} },
+	"members.value": func(g group) []string {
+		var values []string
+		for _, member := range g.Members {
+			values = append(values, member.Value)
+		}
+		return values
+	},
+	"meta.created": func(g group) []string { return []string{g.CreatedAt.Format(time.RFC3339Nano)} },
+	"meta.lastmodified": func(g group) []string {
+		return []string{g.UpdatedAt.Format(time.RFC3339Nano)}
+	},
+}
+
+var clientFilterAttributes = map[string]func(Client) []string{
+	"id":                     func(c Client) []string { return []string{c.ID} },
+	"client_id":              func(c Client) []string { return []string{c.ID} },
+	"name":                   func(c Client) []string { return []string{c.Name} },
+	"scope":                  func(c Client) []string { return c.Scope },
+	"resource_ids":           func(c Client) []string { return c.ResourceIDs },
+	"authorities":            func(c Client) []string { return c.Authorities },
+	"authorized_grant_types": func(c Client) []string { return c.AuthorizedGrantTypes },
+	"redirect_uri":           func(c Client) []string { return c.RedirectURI },
+}
+
+func (u User) FilterValues(attribute string) ([]string, bool) {
+	values, ok := userFilterAttributes[strings.ToLower(attribute)]
+	if !ok {
+		return nil, false
+	}
+
+	return values(u), true
+}
+
+func (g group) FilterValues(attribute string) ([]string, bool) {
+	values, ok := groupFilterAttributes[strings.ToLower(attribute)]
+	if !ok {
+		return nil, false
+	}
+
+	return values(g), true
+}
+
+func (c Client) FilterValues(attribute string) ([]string, bool) {
+	values, ok := clientFilterAttributes[strings.ToLower(attribute)]
+	if !ok {
+		return nil, false
+	}
+
+	return values(c), true
+}
+
+func (collection *Users) Filter(filter common.Filter) (UsersList, error) {
+	if err := filter.Validate(User{}); err != nil {
+		return nil, err
+	}
+
+	list := UsersList{}
+	for _, u := range collection.All() {
+		if filter.Matches(u) {
+			list = append(list, u)
+		}
+	}
+
+	return list, nil
+}
+
+func (collection *Groups) Filter(filter common.Filter) (GroupsList, error) {
+	if err := filter.Validate(group{}); err != nil {
+		return nil, err
+	}
+
+	list := GroupsList{}
+	for _, g := range collection.All() {
+		if filter.Matches(g) {
+			list = append(list, g)
+		}
+	}
+
+	return list, nil
+}
+
+func (collection *Clients) Filter(filter common.Filter) (ClientsList, error) {
+	if err := filter.Validate(Client{}); err != nil {
+		return nil, err
+	}
+
+	list := ClientsList{}
+	for _, c := range collection.All() {
+		if filter.Matches(c) {
+			list = append(list, c)
+		}
+	}
+
+	return list, nil
+}
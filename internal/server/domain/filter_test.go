@@ -0,0 +1,108 @@
+package domain_test
+
+import (
+	"time"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
+	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Filter", func() {
+	var users *domain.Users
+
+	BeforeEach(func() {
+		users = domain.NewUsers()
+		users.Add(domain.User{
+			ID:        "user-1",
+			UserName:  "alice",
+			Emails:    []string{"alice@example.com", "alice@work.example.com"},
+			Origin:    "uaa",
+			Active:    true,
+			CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		})
+		users.Add(domain.User{
+			ID:        "user-2",
+			UserName:  "bob",
+			Emails:    []string{"bob@example.com"},
+			Origin:    "ldap",
+			CreatedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		})
+		users.Add(domain.User{
+			ID:        "user-3",
+			UserName:  "Alfred",
+			Origin:    "uaa",
+			CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		})
+	})
+
+	filter := func(expression string) []string {
+		f, err := common.ParseFilter(expression)
+		Expect(err).NotTo(HaveOccurred())
+
+		list, err := users.Filter(f)
+		Expect(err).NotTo(HaveOccurred())
+
+		var ids []string
+		for _, u := range list {
+			ids = append(ids, u.ID)
+		}
+
+		return ids
+	}
+
+	It("matches every resource when the expression is empty", func() {
+		Expect(filter("")).To(ConsistOf("user-1", "user-2", "user-3"))
+	})
+
+	It("supports the attribute operators", func() {
+		Expect(filter(`userName eq "ALICE"`)).To(ConsistOf("user-1"))
+		Expect(filter(`userName co "l"`)).To(ConsistOf("user-1", "user-3"))
+		Expect(filter(`userName sw "al"`)).To(ConsistOf("user-1", "user-3"))
+		Expect(filter(`emails pr`)).To(ConsistOf("user-1", "user-2"))
+		Expect(filter(`meta.created gt "2020-06-01T00:00:00Z"`)).To(ConsistOf("user-2", "user-3"))
+	})
+
+	It("matches multi-valued attributes when any value matches", func() {
+		Expect(filter(`emails.value eq 'alice@work.example.com'`)).To(ConsistOf("user-1"))
+	})
+
+	It("supports logical operators and grouping", func() {
+		Expect(filter(`origin eq "uaa" and userName sw "alf"`)).To(ConsistOf("user-3"))
+		Expect(filter(`origin eq "ldap" or userName eq "alice"`)).To(ConsistOf("user-1", "user-2"))
+		Expect(filter(`origin eq "uaa" and (userName eq "bob" or active eq true)`)).To(ConsistOf("user-1"))
+		Expect(filter(`not (origin eq "uaa")`)).To(ConsistOf("user-2"))
+	})
+
+	It("unescapes quoted values", func() {
+		users.Add(domain.User{ID: "user-4", UserName: `o"brien`})
+
+		Expect(filter(`userName eq "o\"brien"`)).To(ConsistOf("user-4"))
+	})
+
+	Context("failure cases", func() {
+		It("returns an error when the expression is invalid", func() {
+			for _, expression := range []string{
+				`userName eq`,
+				`userName like "alice"`,
+				`userName eq "alice`,
+				`(userName eq "alice"`,
+				`userName eq "alice" and`,
+				`userName eq alice`,
+			} {
+				_, err := common.ParseFilter(expression)
+				Expect(err).To(HaveOccurred(), expression)
+			}
+		})
+
+		It("returns an error when the attribute is unknown", func() {
+			f, err := common.ParseFilter(`unknown eq "value"`)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = users.Filter(f)
+			Expect(err).To(MatchError(`unknown attribute "unknown"`))
+		})
+	})
+})
@@ -1,6 +1,9 @@
 package domain
 
+import "sync"
+
 type Groups struct {
+	mutex sync.RWMutex
 	store map[string]group
 }
 
@@ -10,20 +13,32 @@ func NewGroups() *Groups {
 	}
 }
 
-func (collection Groups) Add(g group) {
+func (collection *Groups) Add(g group) {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
 	collection.store[g.ID] = g
 }
 
-func (collection Groups) Get(id string) (group, bool) {
+func (collection *Groups) Get(id string) (group, bool) {
+	collection.mutex.RLock()
+	defer collection.mutex.RUnlock()
+
 	g, ok := collection.store[id]
 	return g, ok
 }
 
-func (collection Groups) Update(g group) {
+func (collection *Groups) Update(g group) {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
 	collection.store[g.ID] = g
 }
 
-func (collection Groups) All() []group {
+func (collection *Groups) All() []group {
+	collection.mutex.RLock()
+	defer collection.mutex.RUnlock()
+
 	var groups []group
 	for _, g := range collection.store {
 		groups = append(groups, g)
@@ -32,29 +47,55 @@ func (collection Groups) All() []group {
 	return groups
 }
 
-func (collection Groups) Delete(id string) bool {
+func (collection *Groups) Delete(id string) bool {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
 	_, ok := collection.store[id]
 	delete(collection.store, id)
 	return ok
 }
 
 func (collection *Groups) Clear() {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
 	collection.store = make(map[string]group)
 }
 
-func (collection Groups) AddMember(id string, member Member) (Member, bool) {
+func (collection *Groups) Replace(list []group) {
+	store := make(map[string]group)
+	for _, g := range list {
+		store[g.ID] = g
+	}
+
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
+	collection.store = store
+}
+
+func (collection *Groups) AddMember(id string, member Member) (Member, bool) {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
 	group, ok := collection.store[id]
 	if !ok {
 		return Member{}, false
 	}
 
-	group.Members = append(group.Members, member)
+	members := make([]Member, 0, len(group.Members)+1)
+	members = append(members, group.Members...)
+	group.Members = append(members, member)
 	collection.store[id] = group
 
 	return member, true
 }
 
-func (collection Groups) ListMembers(id string) ([]Member, bool) {
+func (collection *Groups) ListMembers(id string) ([]Member, bool) {
+	collection.mutex.RLock()
+	defer collection.mutex.RUnlock()
+
 	group, ok := collection.store[id]
 	if !ok {
 		return []Member{}, false
@@ -68,7 +109,10 @@ func (collection Groups) ListMembers(id string) ([]Member, bool) {
 	return members, true
 }
 
-func (collection Groups) CheckMembership(id, memberID string) (Member, bool) {
+func (collection *Groups) CheckMembership(id, memberID string) (Member, bool) {
+	collection.mutex.RLock()
+	defer collection.mutex.RUnlock()
+
 	g, ok := collection.store[id]
 	if !ok {
 		return Member{}, false
@@ -83,7 +127,10 @@ func (collection Groups) CheckMembership(id, memberID string) (Member, bool) {
 	return Member{}, false
 }
 
-func (collection Groups) GetByName(name string) (group, bool) {
+func (collection *Groups) GetByName(name string) (group, bool) {
+	collection.mutex.RLock()
+	defer collection.mutex.RUnlock()
+
 	for _, g := range collection.store {
 		if g.DisplayName == name {
 			return g, true
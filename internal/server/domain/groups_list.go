@@ -19,6 +19,17 @@ func (gl GroupsList) ToDocument() documents.GroupListResponse {
 	return doc
 }
 
+func (gl GroupsList) ToPageDocument(startIndex, count int) documents.GroupListResponse {
+	start, end := pageBounds(len(gl), startIndex, count)
+
+	doc := gl[start:end].ToDocument()
+	doc.StartIndex = startIndex
+	doc.ItemsPerPage = count
+	doc.TotalResults = len(gl)
+
+	return doc
+}
+
 type GroupsByDisplayName GroupsList
 
 func (g GroupsByDisplayName) Len() int {
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"fmt"
+	"sync"
+)
+
+type revocations struct {
+	mutex   sync.Mutex
+	tokens  map[string]bool
+	users   map[string]int
+	clients map[string]int
+}
+
+func newRevocations() *revocations {
+	return &revocations{
+		tokens:  map[string]bool{},
+		users:   map[string]int{},
+		clients: map[string]int{},
+	}
+}
+
+func (r *revocations) revokeToken(jti string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.tokens[jti] = true
+}
+
+func (r *revocations) revokeUser(userID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.users[userID]++
+}
+
+func (r *revocations) revokeClient(clientID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.clients[clientID]++
+}
+
+func (r *revocations) clear() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.tokens = map[string]bool{}
+	r.users = map[string]int{}
+	r.clients = map[string]int{}
+}
+
+func (r *revocations) signature(userID, clientID string) string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.signatureFor(userID, clientID)
+}
+
+func (r *revocations) signatureFor(userID, clientID string) string {
+	userGeneration := r.users[userID]
+	clientGeneration := r.clients[clientID]
+	if userGeneration == 0 && clientGeneration == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d:%d", userGeneration, clientGeneration)
+}
+
+func (r *revocations) isRevoked(t Token) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if t.JTI != "" && r.tokens[t.JTI] {
+		return true
+	}
+
+	return t.RevocationSignature != r.signatureFor(t.UserID, t.ClientID)
+}
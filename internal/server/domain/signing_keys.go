@@ -0,0 +1,334 @@
+package domain
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const DefaultAlgorithm = "RS256"
+
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PublicKey  string
+	PrivateKey string
+}
+
+func NewSigningKey(id, algorithm, privateKey string) (SigningKey, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	signingKey := SigningKey{
+		ID:        id,
+		Algorithm: algorithm,
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{
+			Type:  "PUBLIC KEY",
+			Bytes: publicKey,
+		})),
+		PrivateKey: privateKey,
+	}
+
+	return signingKey.withAlgorithm()
+}
+
+func (k SigningKey) Validate() error {
+	_, err := k.withAlgorithm()
+	return err
+}
+
+func (k SigningKey) Method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+func (k SigningKey) Public() crypto.PublicKey {
+	publicKey, err := parsePublicKey(k.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return publicKey
+}
+
+func (k SigningKey) withAlgorithm() (SigningKey, error) {
+	publicKey, err := parsePublicKey(k.PublicKey)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	privateKey, err := parsePrivateKey(k.PrivateKey)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	if k.Algorithm == "" {
+		k.Algorithm = defaultAlgorithm(privateKey)
+	}
+
+	if !supportsAlgorithm(publicKey, k.Algorithm) || !supportsAlgorithm(privateKey.Public(), k.Algorithm) {
+		return SigningKey{}, fmt.Errorf("signing key cannot be used with the %q algorithm", k.Algorithm)
+	}
+
+	return k, nil
+}
+
+func parsePublicKey(publicKey string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block containing public key")
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return x509.ParsePKCS1PublicKey(block.Bytes)
+}
+
+func parsePrivateKey(privateKey string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block containing private key")
+	}
+
+	var key crypto.PrivateKey
+	var err error
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("signing key type is not supported")
+	}
+
+	return signer, nil
+}
+
+func defaultAlgorithm(privateKey crypto.PrivateKey) string {
+	switch k := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		switch k.Curve.Params().BitSize {
+		case 384:
+			return "ES384"
+		case 521:
+			return "ES512"
+		default:
+			return "ES256"
+		}
+	case ed25519.PrivateKey:
+		return "EdDSA"
+	default:
+		return DefaultAlgorithm
+	}
+}
+
+func supportsAlgorithm(publicKey crypto.PublicKey, algorithm string) bool {
+	switch method := jwt.GetSigningMethod(algorithm).(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := publicKey.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		key, ok := publicKey.(*ecdsa.PublicKey)
+		return ok && key.Curve.Params().BitSize == method.CurveBits
+	case *jwt.SigningMethodEd25519:
+		_, ok := publicKey.(ed25519.PublicKey)
+		return ok
+	default:
+		return false
+	}
+}
+
+func (t *Tokens) PublicKey() string {
+	return t.ActiveKey().PublicKey
+}
+
+func (t *Tokens) PrivateKey() string {
+	return t.ActiveKey().PrivateKey
+}
+
+func (t *Tokens) KeyID() string {
+	return t.ActiveKey().ID
+}
+
+func (t *Tokens) ActiveKey() SigningKey {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	key, _ := t.key(t.activeKeyID)
+	return key
+}
+
+func (t *Tokens) Keys() []SigningKey {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	keys := make([]SigningKey, len(t.keys))
+	copy(keys, t.keys)
+
+	return keys
+}
+
+func (t *Tokens) SetKeys(publicKey, privateKey string) error {
+	return t.SetActiveKey(SigningKey{
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+	})
+}
+
+func (t *Tokens) SetActiveKey(key SigningKey) error {
+	key, err := key.withAlgorithm()
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for i := range t.keys {
+		if t.keys[i].ID == t.activeKeyID {
+			key.ID = t.activeKeyID
+			t.keys[i] = key
+		}
+	}
+
+	return nil
+}
+
+func (t *Tokens) SetKeyID(keyID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for i := range t.keys {
+		if t.keys[i].ID == t.activeKeyID {
+			t.keys[i].ID = keyID
+		}
+	}
+
+	t.activeKeyID = keyID
+}
+
+func (t *Tokens) AddKey(key SigningKey) error {
+	if key.ID == "" {
+		return errors.New("signing key is missing an id")
+	}
+
+	key, err := key.withAlgorithm()
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if _, ok := t.key(key.ID); ok {
+		return fmt.Errorf("signing key already exists: %s", key.ID)
+	}
+
+	t.keys = append(t.keys, key)
+
+	return nil
+}
+
+func (t *Tokens) RemoveKey(keyID string) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if keyID == t.activeKeyID {
+		return fmt.Errorf("cannot remove the active signing key: %s", keyID)
+	}
+
+	for i, key := range t.keys {
+		if key.ID == keyID {
+			t.keys = append(t.keys[:i:i], t.keys[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("signing key does not exist: %s", keyID)
+}
+
+func (t *Tokens) ActivateKey(keyID string) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if _, ok := t.key(keyID); !ok {
+		return fmt.Errorf("signing key does not exist: %s", keyID)
+	}
+
+	t.activeKeyID = keyID
+
+	return nil
+}
+
+func (t *Tokens) ReplaceKeys(keys []SigningKey, activeKeyID string) error {
+	var replacements []SigningKey
+	ids := map[string]bool{}
+	for _, key := range keys {
+		if ids[key.ID] {
+			return fmt.Errorf("signing key already exists: %s", key.ID)
+		}
+		ids[key.ID] = true
+
+		key, err := key.withAlgorithm()
+		if err != nil {
+			return err
+		}
+		replacements = append(replacements, key)
+	}
+
+	if !ids[activeKeyID] {
+		return fmt.Errorf("signing key does not exist: %s", activeKeyID)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.keys = replacements
+	t.activeKeyID = activeKeyID
+
+	return nil
+}
+
+func (t *Tokens) verificationKey(keyID string) (SigningKey, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if keyID == "" {
+		keyID = t.activeKeyID
+	}
+
+	return t.key(keyID)
+}
+
+func (t *Tokens) key(keyID string) (SigningKey, bool) {
+	for _, key := range t.keys {
+		if key.ID == keyID {
+			return key, true
+		}
+	}
+
+	return SigningKey{}, false
+}
@@ -2,12 +2,15 @@ package domain
 
 import (
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/pivotal-cf-experimental/warrant/internal/documents"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
 )
 
+const refreshTokenSuffix = "-r"
+
 type Token struct {
 	UserID      string
 	ClientID    string
@@ -15,6 +18,11 @@ type Token struct {
 	Authorities []string
 	Audiences   []string
 	Issuer      string
+	JTI         string
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+
+	RevocationSignature string
 }
 
 func newTokenFromClaims(claims jwt.MapClaims) Token {
@@ -50,27 +58,95 @@ func newTokenFromClaims(claims jwt.MapClaims) Token {
 		t.Audiences = strings.Split(audiences, " ")
 	}
 
+	if issuer, ok := claims["iss"].(string); ok {
+		t.Issuer = issuer
+	}
+
+	if jti, ok := claims["jti"].(string); ok {
+		t.JTI = jti
+	}
+
+	if revocationSignature, ok := claims["rev_sig"].(string); ok {
+		t.RevocationSignature = revocationSignature
+	}
+
+	if issuedAt, ok := claims["iat"].(float64); ok {
+		t.IssuedAt = time.Unix(int64(issuedAt), 0)
+	}
+
+	if expiresAt, ok := claims["exp"].(float64); ok {
+		t.ExpiresAt = time.Unix(int64(expiresAt), 0)
+	}
+
 	return t
 }
 
-func (t Token) ToDocument(privateKey string) documents.TokenResponse {
-	id, err := common.NewUUID()
-	if err != nil {
-		panic(err)
+func (t Token) ToDocument(tokens *Tokens) documents.TokenResponse {
+	if t.JTI == "" {
+		id, err := common.NewUUID()
+		if err != nil {
+			panic(err)
+		}
+
+		t.JTI = id
+	}
+
+	if t.IssuedAt.IsZero() {
+		t.IssuedAt = time.Now()
+	}
+
+	if t.ExpiresAt.IsZero() {
+		t.ExpiresAt = t.IssuedAt.Add(tokens.AccessTokenValidity())
 	}
 
 	return documents.TokenResponse{
-		AccessToken: Tokens{
-			PrivateKey: privateKey,
-		}.Encrypt(t),
-		TokenType: "bearer",
-		ExpiresIn: 5000,
-		Scope:     strings.Join(t.Scopes, " "),
-		JTI:       id,
+		AccessToken: tokens.Encrypt(t),
+		TokenType:   "bearer",
+		ExpiresIn:   int(t.ExpiresAt.Sub(t.IssuedAt).Seconds()),
+		Scope:       strings.Join(t.Scopes, " "),
+		JTI:         t.JTI,
+		Issuer:      t.Issuer,
+	}
+}
+
+func (t Token) ToCheckTokenDocument() documents.CheckTokenResponse {
+	document := documents.CheckTokenResponse{
+		Active:      true,
+		UserID:      t.UserID,
+		ClientID:    t.ClientID,
+		Scope:       t.Scopes,
+		Authorities: t.Authorities,
+		Audiences:   t.Audiences,
+		Issuer:      t.Issuer,
+		JTI:         t.JTI,
+	}
+
+	if !t.ExpiresAt.IsZero() {
+		document.ExpiresAt = t.ExpiresAt.Unix()
+	}
+
+	if !t.IssuedAt.IsZero() {
+		document.IssuedAt = t.IssuedAt.Unix()
+	}
+
+	return document
+}
+
+func (t Token) ToRefreshToken() Token {
+	return Token{
+		UserID:    t.UserID,
+		ClientID:  t.ClientID,
+		Scopes:    t.Scopes,
+		Audiences: t.Audiences,
 		Issuer:    t.Issuer,
+		JTI:       t.JTI + refreshTokenSuffix,
 	}
 }
 
+func (t Token) IsRefreshToken() bool {
+	return strings.HasSuffix(t.JTI, refreshTokenSuffix)
+}
+
 func (t Token) toClaims() jwt.MapClaims {
 	claims := make(jwt.MapClaims)
 
@@ -86,6 +162,22 @@ func (t Token) toClaims() jwt.MapClaims {
 		claims["authorities"] = t.Authorities
 	}
 
+	if len(t.JTI) > 0 {
+		claims["jti"] = t.JTI
+	}
+
+	if len(t.RevocationSignature) > 0 {
+		claims["rev_sig"] = t.RevocationSignature
+	}
+
+	if !t.IssuedAt.IsZero() {
+		claims["iat"] = t.IssuedAt.Unix()
+	}
+
+	if !t.ExpiresAt.IsZero() {
+		claims["exp"] = t.ExpiresAt.Unix()
+	}
+
 	claims["scope"] = t.Scopes
 	claims["aud"] = strings.Join(t.Audiences, " ")
 	claims["iss"] = t.Issuer
@@ -93,31 +185,24 @@ func (t Token) toClaims() jwt.MapClaims {
 	return claims
 }
 
-func (t Token) hasScopes(scopes []string) bool {
-	for _, scope := range scopes {
-		if !contains(t.Scopes, scope) {
-			return false
+func (t Token) satisfies(policy common.Policy) error {
+	for _, audience := range policy.Audiences {
+		if !contains(t.Audiences, audience) {
+			return common.AccessDeniedError{Audience: audience}
 		}
 	}
-	return true
-}
 
-func (t Token) hasAudiences(audiences []string) bool {
-	for _, audience := range audiences {
-		if !contains(t.Audiences, audience) {
-			return false
-		}
+	if len(policy.Scopes) == 0 {
+		return nil
 	}
-	return true
-}
 
-func (t Token) hasAuthorities(authorities []string) bool {
-	for _, authority := range authorities {
-		if !contains(t.Authorities, authority) {
-			return false
+	for _, scope := range policy.Scopes {
+		if contains(t.Scopes, scope) || contains(t.Authorities, scope) {
+			return nil
 		}
 	}
-	return true
+
+	return common.InsufficientScopeError{}
 }
 
 func contains(collection []string, item string) bool {
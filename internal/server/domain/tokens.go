@@ -1,37 +1,109 @@
 package domain
 
 import (
-	"crypto/x509"
-	"encoding/pem"
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
+)
+
+const (
+	DefaultKeyID               = "legacy-token-key"
+	DefaultAccessTokenValidity = 5000 * time.Second
 )
 
 type Tokens struct {
-	DefaultScopes []string
-	PublicKey     string
-	PrivateKey    string
+	mutex                sync.RWMutex
+	keys                 []SigningKey
+	activeKeyID          string
+	issuer               string
+	defaultScopes        []string
+	accessTokenValidity  time.Duration
+	refreshTokenValidity time.Duration
+	revocations          *revocations
+	authorizationCodes   *authorizationCodes
 }
 
 func NewTokens(publicKey, privateKey string, defaultScopes []string) *Tokens {
 	return &Tokens{
-		DefaultScopes: defaultScopes,
-		PublicKey:     publicKey,
-		PrivateKey:    privateKey,
+		keys: []SigningKey{{
+			ID:         DefaultKeyID,
+			Algorithm:  DefaultAlgorithm,
+			PublicKey:  publicKey,
+			PrivateKey: privateKey,
+		}},
+		activeKeyID:         DefaultKeyID,
+		defaultScopes:       defaultScopes,
+		accessTokenValidity: DefaultAccessTokenValidity,
+		revocations:         newRevocations(),
+		authorizationCodes:  newAuthorizationCodes(),
 	}
 }
 
-func (t Tokens) Encrypt(token Token) string {
-	crypt := jwt.NewWithClaims(jwt.SigningMethodRS256, token.toClaims())
-	crypt.Header["kid"] = "legacy-token-key"
+func (t *Tokens) Issuer() string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.issuer
+}
+
+func (t *Tokens) SetIssuer(issuer string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.issuer = issuer
+}
+
+func (t *Tokens) AccessTokenValidity() time.Duration {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.accessTokenValidity
+}
+
+func (t *Tokens) RefreshTokenValidity() time.Duration {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.refreshTokenValidity
+}
+
+func (t *Tokens) SetTokenValidity(accessTokenValidity, refreshTokenValidity time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.accessTokenValidity = accessTokenValidity
+	t.refreshTokenValidity = refreshTokenValidity
+}
+
+func (t *Tokens) DefaultScopes() []string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.defaultScopes
+}
+
+func (t *Tokens) SetDefaultScopes(scopes []string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.defaultScopes = scopes
+}
 
-	block, _ := pem.Decode([]byte(t.PrivateKey))
-	if block == nil {
-		panic("failed to decode PEM block containing public key")
+func (t *Tokens) Encrypt(token Token) string {
+	if t.revocations != nil {
+		token.RevocationSignature = t.revocations.signature(token.UserID, token.ClientID)
 	}
 
-	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	key := t.ActiveKey()
+
+	crypt := jwt.NewWithClaims(key.Method(), token.toClaims())
+	crypt.Header["kid"] = key.ID
+
+	privateKey, err := parsePrivateKey(key.PrivateKey)
 	if err != nil {
 		panic(err)
 	}
@@ -44,24 +116,19 @@ func (t Tokens) Encrypt(token Token) string {
 	return encrypted
 }
 
-func (t Tokens) Decrypt(encryptedToken string) (Token, error) {
+func (t *Tokens) Decrypt(encryptedToken string) (Token, error) {
 	tok, err := jwt.ParseWithClaims(encryptedToken, jwt.MapClaims{}, jwt.Keyfunc(func(token *jwt.Token) (interface{}, error) {
-		switch token.Method {
-		case jwt.SigningMethodRS256, jwt.SigningMethodRS384, jwt.SigningMethodRS512:
-			block, _ := pem.Decode([]byte(t.PublicKey))
-			if block == nil {
-				return nil, errors.New("failed to decode PEM block containing public key")
-			}
-
-			publicKey, err := x509.ParsePKCS1PublicKey(block.Bytes)
-			if err != nil {
-				return nil, err
-			}
-
-			return publicKey, nil
-		default:
+		keyID, _ := token.Header["kid"].(string)
+		key, ok := t.verificationKey(keyID)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", keyID)
+		}
+
+		if token.Method.Alg() != key.Algorithm {
 			return nil, errors.New("Unsupported signing method")
 		}
+
+		return key.Public(), nil
 	}))
 	if err != nil {
 		return Token{}, err
@@ -71,30 +138,60 @@ func (t Tokens) Decrypt(encryptedToken string) (Token, error) {
 		return Token{}, errors.New("token is invalid")
 	}
 
-	return newTokenFromClaims(tok.Claims.(jwt.MapClaims)), nil
+	token := newTokenFromClaims(tok.Claims.(jwt.MapClaims))
+	if t.revocations != nil && t.revocations.isRevoked(token) {
+		return Token{}, errors.New("token has been revoked")
+	}
+
+	return token, nil
 }
 
-func (t Tokens) Validate(encryptedToken string, expectedToken Token) bool {
-	decryptedToken, err := t.Decrypt(encryptedToken)
-	if err != nil {
-		return false
-	}
+func (t *Tokens) RevokeToken(jti string) {
+	t.revocations.revokeToken(jti)
+}
+
+func (t *Tokens) RevokeUserTokens(userID string) {
+	t.revocations.revokeUser(userID)
+}
+
+func (t *Tokens) RevokeClientTokens(clientID string) {
+	t.revocations.revokeClient(clientID)
+}
 
-	return t.validate(decryptedToken, expectedToken)
+func (t *Tokens) ClearRevocations() {
+	t.revocations.clear()
 }
 
-func (t Tokens) validate(tok, expected Token) bool {
-	if ok := tok.hasAudiences(expected.Audiences); !ok {
-		return false
+func (t *Tokens) IssueAuthorizationCode(code AuthorizationCode) string {
+	return t.authorizationCodes.issue(code)
+}
+
+func (t *Tokens) RedeemAuthorizationCode(value string) (AuthorizationCode, bool) {
+	return t.authorizationCodes.redeem(value)
+}
+
+func (t *Tokens) ClearAuthorizationCodes() {
+	t.authorizationCodes.clear()
+}
+
+func (t *Tokens) DecryptAccessToken(encryptedToken string) (Token, error) {
+	token, err := t.Decrypt(encryptedToken)
+	if err != nil {
+		return Token{}, err
 	}
 
-	if ok := tok.hasScopes(expected.Scopes); !ok {
-		return false
+	if token.IsRefreshToken() {
+		return Token{}, errors.New("Invalid access token")
 	}
 
-	if ok := tok.hasAuthorities(expected.Authorities); !ok {
-		return false
+	return token, nil
+}
+
+func (t *Tokens) Authorize(encryptedToken string, policy common.Policy) error {
+	token, err := t.DecryptAccessToken(encryptedToken)
+	if err != nil {
+		return err
 	}
 
-	return true
+	return token.satisfies(policy)
 }
@@ -1,6 +1,9 @@
 package domain
 
+import "sync"
+
 type Users struct {
+	mutex sync.RWMutex
 	store map[string]User
 }
 
@@ -10,7 +13,10 @@ func NewUsers() *Users {
 	}
 }
 
-func (collection Users) All() []User {
+func (collection *Users) All() []User {
+	collection.mutex.RLock()
+	defer collection.mutex.RUnlock()
+
 	var users []User
 	for _, u := range collection.store {
 		users = append(users, u)
@@ -18,20 +24,32 @@ func (collection Users) All() []User {
 	return users
 }
 
-func (collection Users) Add(u User) {
+func (collection *Users) Add(u User) {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
 	collection.store[u.ID] = u
 }
 
-func (collection Users) Update(u User) {
+func (collection *Users) Update(u User) {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
 	collection.store[u.ID] = u
 }
 
-func (collection Users) Get(id string) (User, bool) {
+func (collection *Users) Get(id string) (User, bool) {
+	collection.mutex.RLock()
+	defer collection.mutex.RUnlock()
+
 	u, ok := collection.store[id]
 	return u, ok
 }
 
-func (collection Users) GetByName(name string) (User, bool) {
+func (collection *Users) GetByName(name string) (User, bool) {
+	collection.mutex.RLock()
+	defer collection.mutex.RUnlock()
+
 	for _, u := range collection.store {
 		if u.UserName == name {
 			return u, true
@@ -41,16 +59,34 @@ func (collection Users) GetByName(name string) (User, bool) {
 	return User{}, false
 }
 
-func (collection Users) Delete(id string) bool {
+func (collection *Users) Delete(id string) bool {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
 	_, ok := collection.store[id]
 	delete(collection.store, id)
 	return ok
 }
 
 func (collection *Users) Clear() {
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
 	collection.store = make(map[string]User)
 }
 
+func (collection *Users) Replace(list []User) {
+	store := make(map[string]User)
+	for _, u := range list {
+		store[u.ID] = u
+	}
+
+	collection.mutex.Lock()
+	defer collection.mutex.Unlock()
+
+	collection.store = store
+}
+
 type ByEmail UsersList
 
 func (users ByEmail) Len() int {
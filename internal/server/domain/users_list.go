@@ -19,3 +19,14 @@ func (ul UsersList) ToDocument() documents.UserListResponse {
 
 	return doc
 }
+
+func (ul UsersList) ToPageDocument(startIndex, count int) documents.UserListResponse {
+	start, end := pageBounds(len(ul), startIndex, count)
+
+	doc := ul[start:end].ToDocument()
+	doc.StartIndex = startIndex
+	doc.ItemsPerPage = count
+	doc.TotalResults = len(ul)
+
+	return doc
+}
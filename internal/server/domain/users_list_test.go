@@ -24,4 +24,44 @@ var _ = Describe("UsersList", func() {
 			Expect(doc.Resources).To(Equal([]documents.UserResponse{}))
 		})
 	})
+
+	Describe("ToPageDocument", func() {
+		var list domain.UsersList
+
+		BeforeEach(func() {
+			list = domain.UsersList{
+				domain.User{ID: "user-1"},
+				domain.User{ID: "user-2"},
+				domain.User{ID: "user-3"},
+			}
+		})
+
+		It("returns the requested page of resources", func() {
+			doc := list.ToPageDocument(2, 1)
+			Expect(doc.Resources).To(HaveLen(1))
+			Expect(doc.Resources[0].ID).To(Equal("user-2"))
+			Expect(doc.StartIndex).To(Equal(2))
+			Expect(doc.ItemsPerPage).To(Equal(1))
+			Expect(doc.TotalResults).To(Equal(3))
+		})
+
+		It("truncates the final page", func() {
+			doc := list.ToPageDocument(3, 10)
+			Expect(doc.Resources).To(HaveLen(1))
+			Expect(doc.Resources[0].ID).To(Equal("user-3"))
+		})
+
+		It("returns an empty page when the start index is beyond the list", func() {
+			doc := list.ToPageDocument(5, 10)
+			Expect(doc.Resources).To(Equal([]documents.UserResponse{}))
+			Expect(doc.TotalResults).To(Equal(3))
+		})
+
+		It("returns the rest of the list when the count is the largest int", func() {
+			doc := list.ToPageDocument(2, int(^uint(0)>>1))
+			Expect(doc.Resources).To(HaveLen(2))
+			Expect(doc.Resources[0].ID).To(Equal("user-2"))
+			Expect(doc.Resources[1].ID).To(Equal("user-3"))
+		})
+	})
 })
@@ -6,7 +6,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"regexp"
-	"strings"
 
 	"github.com/pivotal-cf-experimental/warrant/internal/documents"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
@@ -15,19 +14,9 @@ import (
 
 type addMemberHandler struct {
 	groups *domain.Groups
-	tokens *domain.Tokens
 }
 
 func (h addMemberHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
-	if ok := h.tokens.Validate(token, domain.Token{
-		Audiences:   []string{"scim"},
-		Authorities: []string{"scim.read"},
-	}); !ok {
-		common.JSONError(w, http.StatusUnauthorized, "Full authentication is required to access this resource", "unauthorized")
-		return
-	}
-
 	matches := regexp.MustCompile(`/Groups/(.*)/members$`).FindStringSubmatch(req.URL.Path)
 	id := matches[1]
 
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
-	"strings"
 
 	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
@@ -13,19 +12,9 @@ import (
 
 type checkMembershipHandler struct {
 	groups *domain.Groups
-	tokens *domain.Tokens
 }
 
 func (h checkMembershipHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
-	if ok := h.tokens.Validate(token, domain.Token{
-		Audiences:   []string{"scim"},
-		Authorities: []string{"scim.read"},
-	}); !ok {
-		common.JSONError(w, http.StatusUnauthorized, "Full authentication is required to access this resource", "unauthorized")
-		return
-	}
-
 	matches := regexp.MustCompile(`/Groups/(.*)/members/(.*)$`).FindStringSubmatch(req.URL.Path)
 	groupID := matches[1]
 	memberID := matches[2]
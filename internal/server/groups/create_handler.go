@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"strings"
 
 	"github.com/pivotal-cf-experimental/warrant/internal/documents"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
@@ -14,19 +13,9 @@ import (
 
 type createHandler struct {
 	groups *domain.Groups
-	tokens *domain.Tokens
 }
 
 func (h createHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
-	if ok := h.tokens.Validate(token, domain.Token{
-		Audiences:   []string{"scim"},
-		Authorities: []string{"scim.write"},
-	}); !ok {
-		common.JSONError(w, http.StatusUnauthorized, "Full authentication is required to access this resource", "unauthorized")
-		return
-	}
-
 	requestBody, err := ioutil.ReadAll(req.Body)
 	if err != nil {
 		panic(err)
@@ -2,20 +2,38 @@ package groups
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
 )
 
+var (
+	readPolicy = common.Policy{
+		Audiences: []string{"scim"},
+		Scopes:    []string{"scim.read", "uaa.admin"},
+	}
+
+	writePolicy = common.Policy{
+		Audiences: []string{"scim"},
+		Scopes:    []string{"scim.write", "uaa.admin"},
+	}
+
+	updatePolicy = common.Policy{
+		Audiences: []string{"scim"},
+		Scopes:    []string{"scim.write", "groups.update", "uaa.admin"},
+	}
+)
+
 func NewRouter(groups *domain.Groups, tokens *domain.Tokens) *mux.Router {
 	router := mux.NewRouter()
 
-	router.Handle("/Groups", createHandler{groups, tokens}).Methods("POST")
-	router.Handle("/Groups", listHandler{groups, tokens}).Methods("GET")
-	router.Handle("/Groups/{guid}", updateHandler{groups, tokens}).Methods("PUT")
-	router.Handle("/Groups/{guid}", getHandler{groups, tokens}).Methods("GET")
-	router.Handle("/Groups/{guid}", deleteHandler{groups, tokens}).Methods("DELETE")
-	router.Handle("/Groups/{guid}/members", listMembersHandler{groups, tokens}).Methods("GET")
-	router.Handle("/Groups/{guid}/members", addMemberHandler{groups, tokens}).Methods("POST")
-	router.Handle("/Groups/{guid}/members/{guid}", checkMembershipHandler{groups, tokens}).Methods("GET")
+	router.Handle("/Groups", common.Authorize(tokens, writePolicy, createHandler{groups})).Methods("POST")
+	router.Handle("/Groups", common.Authorize(tokens, readPolicy, listHandler{groups})).Methods("GET")
+	router.Handle("/Groups/{guid}", common.Authorize(tokens, updatePolicy, updateHandler{groups})).Methods("PUT")
+	router.Handle("/Groups/{guid}", common.Authorize(tokens, readPolicy, getHandler{groups})).Methods("GET")
+	router.Handle("/Groups/{guid}", common.Authorize(tokens, writePolicy, deleteHandler{groups})).Methods("DELETE")
+	router.Handle("/Groups/{guid}/members", common.Authorize(tokens, readPolicy, listMembersHandler{groups})).Methods("GET")
+	router.Handle("/Groups/{guid}/members", common.Authorize(tokens, updatePolicy, addMemberHandler{groups})).Methods("POST")
+	router.Handle("/Groups/{guid}/members/{guid}", common.Authorize(tokens, readPolicy, checkMembershipHandler{groups})).Methods("GET")
 
 	return router
 }
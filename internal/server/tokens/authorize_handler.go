@@ -26,7 +26,7 @@ func (h authorizeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	clientID := requestQuery.Get("client_id")
 	responseType := requestQuery.Get("response_type")
 
-	if responseType != "token" {
+	if responseType != "token" && responseType != "code" {
 		h.redirectToLogin(w)
 		return
 	}
@@ -59,7 +59,7 @@ func (h authorizeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	scopes := []string{}
 	requestedScopes := strings.Split(req.Form.Get("scope"), " ")
 	for _, requestedScope := range requestedScopes {
-		if contains(h.tokens.DefaultScopes, requestedScope) {
+		if contains(h.tokens.DefaultScopes(), requestedScope) {
 			scopes = append(scopes, requestedScope)
 		}
 	}
@@ -77,14 +77,19 @@ func (h authorizeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	redirectURI := requestQuery.Get("redirect_uri")
+
+	if responseType == "code" {
+		h.issueAuthorizationCode(w, client, user, scopes, redirectURI, requestQuery)
+		return
+	}
+
 	t := h.tokens.Encrypt(domain.Token{
 		UserID:    user.ID,
 		Scopes:    scopes,
 		Audiences: []string{},
 	})
 
-	redirectURI := requestQuery.Get("redirect_uri")
-
 	query := url.Values{
 		"token_type":   []string{"bearer"},
 		"access_token": []string{t},
@@ -98,6 +103,47 @@ func (h authorizeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	w.WriteHeader(http.StatusFound)
 }
 
+func (h authorizeHandler) issueAuthorizationCode(w http.ResponseWriter, client domain.Client, user domain.User, scopes []string, redirectURI string, requestQuery url.Values) {
+	if !contains(client.AuthorizedGrantTypes, "authorization_code") {
+		common.JSONError(w, http.StatusBadRequest, "Unauthorized grant type: authorization_code", "unauthorized_client")
+		return
+	}
+
+	if redirectURI == "" && len(client.RedirectURI) > 0 {
+		redirectURI = client.RedirectURI[0]
+	}
+
+	if !contains(client.RedirectURI, redirectURI) {
+		common.JSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid redirect %s did not match one of the registered values", redirectURI), "invalid_request")
+		return
+	}
+
+	code := h.tokens.IssueAuthorizationCode(domain.AuthorizationCode{
+		ClientID:            client.ID,
+		UserID:              user.ID,
+		Scopes:              scopes,
+		RedirectURI:         requestQuery.Get("redirect_uri"),
+		CodeChallenge:       requestQuery.Get("code_challenge"),
+		CodeChallengeMethod: requestQuery.Get("code_challenge_method"),
+	})
+
+	location, err := url.Parse(redirectURI)
+	if err != nil {
+		common.JSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid redirect %s", redirectURI), "invalid_request")
+		return
+	}
+
+	query := location.Query()
+	query.Set("code", code)
+	if state := requestQuery.Get("state"); state != "" {
+		query.Set("state", state)
+	}
+	location.RawQuery = query.Encode()
+
+	w.Header().Set("Location", location.String())
+	w.WriteHeader(http.StatusFound)
+}
+
 func contains(scopeList []string, requestedScope string) bool {
 	for _, scope := range scopeList {
 		if scope == requestedScope {
@@ -74,7 +74,7 @@ var _ = Describe("authorizeHandler", func() {
 		usersCollection.Add(user)
 
 		router = tokens.NewRouter(tokensCollection,
-			usersCollection, clientsCollection, common.TestPublicKey, common.TestPrivateKey, hasURL{})
+			usersCollection, clientsCollection, hasURL{})
 	})
 
 	It("returns a valid token when there is no overlap between client and user scopes", func() {
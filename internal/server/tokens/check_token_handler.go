@@ -0,0 +1,52 @@
+package tokens
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
+	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
+)
+
+type checkTokenHandler struct {
+	tokens  *domain.Tokens
+	clients *domain.Clients
+}
+
+func (h checkTokenHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	clientID, clientSecret, ok := req.BasicAuth()
+	if !ok {
+		common.JSONError(w, http.StatusUnauthorized, "Full authentication is required to access this resource", "unauthorized")
+		return
+	}
+
+	client, ok := h.clients.Get(clientID)
+	if !ok || client.Secret != clientSecret {
+		common.JSONError(w, http.StatusUnauthorized, "Bad credentials", "invalid_client")
+		return
+	}
+
+	if !client.HasAuthority("uaa.resource") {
+		common.JSONError(w, http.StatusForbidden, "Access is denied", "access_denied")
+		return
+	}
+
+	err := req.ParseForm()
+	if err != nil {
+		panic(err)
+	}
+
+	t, err := h.tokens.Decrypt(req.Form.Get("token"))
+	if err != nil || t.IsRefreshToken() {
+		common.JSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid token: %s", req.Form.Get("token")), "invalid_token")
+		return
+	}
+
+	response, err := json.Marshal(t.ToCheckTokenDocument())
+	if err != nil {
+		panic(err)
+	}
+
+	w.Write(response)
+}
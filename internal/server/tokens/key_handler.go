@@ -1,45 +1,18 @@
 package tokens
 
 import (
-	"crypto/x509"
-	"encoding/base64"
 	"encoding/json"
-	"encoding/pem"
-	"math/big"
 	"net/http"
 
-	"github.com/pivotal-cf-experimental/warrant/internal/documents"
+	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
 )
 
 type keyHandler struct {
-	publicKey string
+	tokens *domain.Tokens
 }
 
 func (h keyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	pem, _ := pem.Decode([]byte(h.publicKey))
-
-	if pem == nil {
-		panic("No PEM data was included in the public key")
-	}
-
-	rsaPublicKey, err := x509.ParsePKCS1PublicKey(pem.Bytes)
-	if err != nil {
-		panic(err)
-	}
-
-	exponentBytes := big.NewInt(int64(rsaPublicKey.E)).Bytes()
-	modulusBytes := rsaPublicKey.N.Bytes()
-
-	response, err := json.Marshal(documents.TokenKeyResponse{
-		Kid:   "legacy-token-key",
-		Alg:   "SHA256withRSA",
-		Value: h.publicKey,
-		Kty:   "RSA",
-		Use:   "sig",
-		N:     base64.RawURLEncoding.EncodeToString(modulusBytes),
-		E:     base64.RawURLEncoding.EncodeToString(exponentBytes),
-	})
-
+	response, err := json.Marshal(keyDocument(h.tokens.ActiveKey()))
 	if err != nil {
 		panic(err)
 	}
@@ -1,58 +1,39 @@
 package tokens
 
 import (
-	"crypto/x509"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"encoding/base64"
 	"encoding/json"
-	"encoding/pem"
 	"math/big"
 	"net/http"
 
 	"github.com/pivotal-cf-experimental/warrant/internal/documents"
+	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
 )
 
 type keysHandler struct {
-	publicKey string
+	tokens *domain.Tokens
 }
 
 func (h keysHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	pem, _ := pem.Decode([]byte(h.publicKey))
+	var keys []documents.TokenKeyResponse
+	for _, key := range h.tokens.Keys() {
+		document := keyDocument(key)
+		keys = append(keys, document)
 
-	if pem == nil {
-		panic("No PEM data was included in the public key")
+		// The default key is also published under the "token-key" id,
+		// matching a UAA that has not been configured with a key id.
+		if key.ID == domain.DefaultKeyID {
+			document.Kid = "token-key"
+			keys = append(keys, document)
+		}
 	}
 
-	rsaPublicKey, err := x509.ParsePKCS1PublicKey(pem.Bytes)
-	if err != nil {
-		panic(err)
-	}
-
-	exponentBytes := big.NewInt(int64(rsaPublicKey.E)).Bytes()
-	modulusBytes := rsaPublicKey.N.Bytes()
-
 	response, err := json.Marshal(documents.TokenKeysResponse{
-		Keys: []documents.TokenKeyResponse{
-			{
-				Kid:   "legacy-token-key",
-				Alg:   "SHA256withRSA",
-				Value: h.publicKey,
-				Kty:   "RSA",
-				Use:   "sig",
-				N:     base64.RawURLEncoding.EncodeToString(modulusBytes),
-				E:     base64.RawURLEncoding.EncodeToString(exponentBytes),
-			},
-			{
-				Kid:   "token-key",
-				Alg:   "SHA256withRSA",
-				Value: h.publicKey,
-				Kty:   "RSA",
-				Use:   "sig",
-				N:     base64.RawURLEncoding.EncodeToString(modulusBytes),
-				E:     base64.RawURLEncoding.EncodeToString(exponentBytes),
-			},
-		},
+		Keys: keys,
 	})
-
 	if err != nil {
 		panic(err)
 	}
@@ -60,3 +41,58 @@ func (h keysHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(response)
 }
+
+func keyDocument(key domain.SigningKey) documents.TokenKeyResponse {
+	document := documents.TokenKeyResponse{
+		Kid:   key.ID,
+		Alg:   keyAlgorithm(key.Algorithm),
+		Value: key.PublicKey,
+		Use:   "sig",
+	}
+
+	switch publicKey := key.Public().(type) {
+	case *rsa.PublicKey:
+		document.Kty = "RSA"
+		document.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		document.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+
+	case *ecdsa.PublicKey:
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+
+		document.Kty = "EC"
+		document.Crv = publicKey.Curve.Params().Name
+		document.X = base64.RawURLEncoding.EncodeToString(padded(publicKey.X.Bytes(), size))
+		document.Y = base64.RawURLEncoding.EncodeToString(padded(publicKey.Y.Bytes(), size))
+
+	case ed25519.PublicKey:
+		document.Kty = "OKP"
+		document.Crv = "Ed25519"
+		document.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	}
+
+	return document
+}
+
+// keyAlgorithm returns the name UAA gives to the algorithm of a key.
+// UAA describes RSA keys using their Java names, but uses the JSON
+// Web Algorithm names for all other keys.
+func keyAlgorithm(algorithm string) string {
+	switch algorithm {
+	case "RS256":
+		return "SHA256withRSA"
+	case "RS384":
+		return "SHA384withRSA"
+	case "RS512":
+		return "SHA512withRSA"
+	default:
+		return algorithm
+	}
+}
+
+func padded(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+
+	return append(make([]byte, size-len(b)), b...)
+}
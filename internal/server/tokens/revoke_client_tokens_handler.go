@@ -0,0 +1,29 @@
+package tokens
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
+	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
+)
+
+type revokeClientTokensHandler struct {
+	tokens  *domain.Tokens
+	clients *domain.Clients
+}
+
+func (h revokeClientTokensHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	matches := regexp.MustCompile(`/oauth/token/revoke/client/(.*)$`).FindStringSubmatch(req.URL.Path)
+	id := matches[1]
+
+	if _, ok := h.clients.Get(id); !ok {
+		common.NotFound(w, fmt.Sprintf("Client %s does not exist", id))
+		return
+	}
+
+	h.tokens.RevokeClientTokens(id)
+
+	w.WriteHeader(http.StatusOK)
+}
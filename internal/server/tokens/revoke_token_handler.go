@@ -0,0 +1,19 @@
+package tokens
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
+)
+
+type revokeTokenHandler struct {
+	tokens *domain.Tokens
+}
+
+func (h revokeTokenHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	matches := regexp.MustCompile(`/oauth/token/revoke/(.*)$`).FindStringSubmatch(req.URL.Path)
+	h.tokens.RevokeToken(matches[1])
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,29 @@
+package tokens
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
+	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
+)
+
+type revokeUserTokensHandler struct {
+	tokens *domain.Tokens
+	users  *domain.Users
+}
+
+func (h revokeUserTokensHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	matches := regexp.MustCompile(`/oauth/token/revoke/user/(.*)$`).FindStringSubmatch(req.URL.Path)
+	id := matches[1]
+
+	if _, ok := h.users.Get(id); !ok {
+		common.NotFound(w, fmt.Sprintf("User %s does not exist", id))
+		return
+	}
+
+	h.tokens.RevokeUserTokens(id)
+
+	w.WriteHeader(http.StatusOK)
+}
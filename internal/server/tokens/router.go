@@ -2,23 +2,30 @@ package tokens
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
 )
 
+var revokePolicy = common.Policy{
+	Scopes: []string{"tokens.revoke", "uaa.admin"},
+}
+
 func NewRouter(
 	tokens *domain.Tokens,
 	users *domain.Users,
 	clients *domain.Clients,
-	publicKey string,
-	privateKey string,
 	urlFinder urlFinder) *mux.Router {
 
 	router := mux.NewRouter()
 
-	router.Handle("/oauth/token", tokenHandler{clients, users, urlFinder, privateKey}).Methods("POST")
+	router.Handle("/oauth/token", tokenHandler{tokens, clients, users, urlFinder}).Methods("POST")
 	router.Handle("/oauth/authorize", authorizeHandler{tokens, users, clients}).Methods("POST")
-	router.Handle("/token_key", keyHandler{publicKey}).Methods("GET")
-	router.Handle("/token_keys", keysHandler{publicKey}).Methods("GET")
+	router.Handle("/oauth/token/revoke/user/{id}", common.Authorize(tokens, revokePolicy, revokeUserTokensHandler{tokens, users})).Methods("GET")
+	router.Handle("/oauth/token/revoke/client/{id}", common.Authorize(tokens, revokePolicy, revokeClientTokensHandler{tokens, clients})).Methods("GET")
+	router.Handle("/oauth/token/revoke/{id}", common.Authorize(tokens, revokePolicy, revokeTokenHandler{tokens})).Methods("DELETE")
+	router.Handle("/check_token", checkTokenHandler{tokens, clients}).Methods("POST")
+	router.Handle("/token_key", keyHandler{tokens}).Methods("GET")
+	router.Handle("/token_keys", keysHandler{tokens}).Methods("GET")
 
 	return router
 }
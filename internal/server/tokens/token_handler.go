@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/pivotal-cf-experimental/warrant/internal/documents"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
 )
@@ -14,10 +16,10 @@ type urlFinder interface {
 }
 
 type tokenHandler struct {
-	clients    *domain.Clients
-	users      *domain.Users
-	urlFinder  urlFinder
-	privateKey string
+	tokens    *domain.Tokens
+	clients   *domain.Clients
+	users     *domain.Users
+	urlFinder urlFinder
 }
 
 func (h tokenHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
@@ -27,36 +29,121 @@ func (h tokenHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 
 	clientID := req.Form.Get("client_id")
-
 	client, ok := h.clients.Get(clientID)
 	if !ok {
 		common.JSONError(w, http.StatusUnauthorized, fmt.Sprintf("No client with requested id: %s", clientID), "invalid_client")
 		return
 	}
 
-	var t domain.Token
-	if req.Form.Get("grant_type") == "client_credentials" {
-		t.ClientID = clientID
-		t.Scopes = client.Scope
-		t.Authorities = client.Authorities
-		t.Audiences = client.ResourceIDs
-		t.Issuer = fmt.Sprintf("%s/oauth/token", h.urlFinder.URL())
-	} else {
+	var document documents.TokenResponse
+	switch req.Form.Get("grant_type") {
+	case "client_credentials":
+		t := domain.Token{
+			ClientID:    clientID,
+			Scopes:      client.Scope,
+			Authorities: client.Authorities,
+			Audiences:   client.ResourceIDs,
+			Issuer:      h.issuer(),
+		}
+
+		document = t.ToDocument(h.tokens)
+
+	case "refresh_token":
+		if !contains(client.AuthorizedGrantTypes, "refresh_token") {
+			common.JSONError(w, http.StatusUnauthorized, "Unauthorized grant type: refresh_token", "invalid_client")
+			return
+		}
+
+		refreshToken := req.Form.Get("refresh_token")
+		t, err := h.tokens.Decrypt(refreshToken)
+		if err != nil || !t.IsRefreshToken() || t.ClientID != clientID {
+			common.JSONError(w, http.StatusUnauthorized, fmt.Sprintf("Invalid refresh token: %s", refreshToken), "invalid_token")
+			return
+		}
+
+		if _, ok := h.users.Get(t.UserID); !ok {
+			common.JSONError(w, http.StatusUnauthorized, fmt.Sprintf("Invalid refresh token: %s", refreshToken), "invalid_token")
+			return
+		}
+
+		t.JTI = ""
+		t.IssuedAt = time.Time{}
+		t.ExpiresAt = time.Time{}
+		t.Issuer = h.issuer()
+		document = t.ToDocument(h.tokens)
+		document.RefreshToken = refreshToken
+
+	case "authorization_code":
+		code, ok := h.tokens.RedeemAuthorizationCode(req.Form.Get("code"))
+		if !ok || code.ClientID != clientID {
+			common.JSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid authorization code: %s", req.Form.Get("code")), "invalid_grant")
+			return
+		}
+
+		if code.RedirectURI != "" && code.RedirectURI != req.Form.Get("redirect_uri") {
+			common.JSONError(w, http.StatusBadRequest, "Redirect URI mismatch.", "invalid_grant")
+			return
+		}
+
+		if !code.VerifyCodeChallenge(req.Form.Get("code_verifier")) {
+			common.JSONError(w, http.StatusBadRequest, "Invalid code verifier", "invalid_grant")
+			return
+		}
+
+		t := domain.Token{
+			ClientID: clientID,
+			Scopes:   code.Scopes,
+			UserID:   code.UserID,
+		}
+
+		document = h.userTokenDocument(t, client)
+
+	default:
 		user, ok := h.users.GetByName(req.Form.Get("username"))
 		if !ok {
 			common.JSONError(w, http.StatusNotFound, fmt.Sprintf("User %s does not exist", req.Form.Get("username")), "scim_resource_not_found")
 			return
 		}
 
-		t.ClientID = clientID
-		t.Scopes = client.Scope
-		t.UserID = user.ID
+		t := domain.Token{
+			ClientID: clientID,
+			Scopes:   client.Scope,
+			UserID:   user.ID,
+		}
+
+		document = h.userTokenDocument(t, client)
 	}
 
-	response, err := json.Marshal(t.ToDocument(h.privateKey))
+	response, err := json.Marshal(document)
 	if err != nil {
 		panic(err)
 	}
 
 	w.Write(response)
 }
+
+func (h tokenHandler) userTokenDocument(t domain.Token, client domain.Client) documents.TokenResponse {
+	t.Issuer = h.issuer()
+	document := t.ToDocument(h.tokens)
+	if contains(client.AuthorizedGrantTypes, "refresh_token") {
+		t.JTI = document.JTI
+
+		refreshToken := t.ToRefreshToken()
+		if validity := h.tokens.RefreshTokenValidity(); validity > 0 {
+			refreshToken.IssuedAt = time.Now()
+			refreshToken.ExpiresAt = refreshToken.IssuedAt.Add(validity)
+		}
+
+		document.RefreshToken = h.tokens.Encrypt(refreshToken)
+	}
+
+	return document
+}
+
+func (h tokenHandler) issuer() string {
+	if issuer := h.tokens.Issuer(); issuer != "" {
+		return issuer
+	}
+
+	return fmt.Sprintf("%s/oauth/token", h.urlFinder.URL())
+}
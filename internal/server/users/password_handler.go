@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"net/http"
 	"regexp"
-	"strings"
 
 	"github.com/pivotal-cf-experimental/warrant/internal/documents"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
@@ -17,7 +16,7 @@ type passwordHandler struct {
 }
 
 func (h passwordHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	token := common.BearerToken(req)
 	matches := regexp.MustCompile(`/Users/(.*)/password$`).FindStringSubmatch(req.URL.Path)
 	id := matches[1]
 
@@ -43,14 +42,11 @@ func (h passwordHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 }
 
 func (h passwordHandler) canUpdateUserPassword(userID, tokenHeader, existingPassword, givenPassword string) bool {
-	if h.tokens.Validate(tokenHeader, domain.Token{
-		Audiences:   []string{"password"},
-		Authorities: []string{"password.write"},
-	}) {
+	if err := h.tokens.Authorize(tokenHeader, passwordPolicy); err == nil {
 		return true
 	}
 
-	t, err := h.tokens.Decrypt(tokenHeader)
+	t, err := h.tokens.DecryptAccessToken(tokenHeader)
 	if err != nil {
 		return false
 	}
@@ -2,17 +2,35 @@ package users
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
 )
 
+var (
+	readPolicy = common.Policy{
+		Audiences: []string{"scim"},
+		Scopes:    []string{"scim.read", "uaa.admin"},
+	}
+
+	writePolicy = common.Policy{
+		Audiences: []string{"scim"},
+		Scopes:    []string{"scim.write", "uaa.admin"},
+	}
+
+	passwordPolicy = common.Policy{
+		Audiences: []string{"password"},
+		Scopes:    []string{"password.write"},
+	}
+)
+
 func NewRouter(users *domain.Users, tokens *domain.Tokens) *mux.Router {
 	router := mux.NewRouter()
 
-	router.Handle("/Users", createHandler{users, tokens}).Methods("POST")
-	router.Handle("/Users", listHandler{users, tokens}).Methods("GET")
-	router.Handle("/Users/{guid}", getHandler{users, tokens}).Methods("GET")
-	router.Handle("/Users/{guid}", deleteHandler{users, tokens}).Methods("DELETE")
-	router.Handle("/Users/{guid}", updateHandler{users, tokens}).Methods("PUT")
+	router.Handle("/Users", common.Authorize(tokens, writePolicy, createHandler{users})).Methods("POST")
+	router.Handle("/Users", common.Authorize(tokens, readPolicy, listHandler{users})).Methods("GET")
+	router.Handle("/Users/{guid}", common.Authorize(tokens, readPolicy, getHandler{users})).Methods("GET")
+	router.Handle("/Users/{guid}", common.Authorize(tokens, writePolicy, deleteHandler{users})).Methods("DELETE")
+	router.Handle("/Users/{guid}", common.Authorize(tokens, writePolicy, updateHandler{users})).Methods("PUT")
 	router.Handle("/Users/{guid}/password", passwordHandler{users, tokens}).Methods("PUT")
 
 	return router
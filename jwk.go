@@ -0,0 +1,144 @@
+package warrant
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/documents"
+)
+
+// newSigningKey returns the SigningKey described by the given key document.
+// UAA includes a PEM encoded "value" for each key, but other identity
+// providers only publish keys in the JSON Web Key format, in which case the
+// PEM encoding is derived from the key parameters.
+func newSigningKey(document documents.TokenKeyResponse) (SigningKey, error) {
+	key := SigningKey{
+		KeyId:     document.Kid,
+		Algorithm: document.Alg,
+		Value:     document.Value,
+	}
+
+	if key.Algorithm == "" {
+		key.Algorithm = jwkAlgorithm(document)
+	}
+
+	if key.Value != "" {
+		return key, nil
+	}
+
+	publicKey, err := jwkPublicKey(document)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	if publicKey == nil {
+		return key, nil
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	key.Value = string(pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: der,
+	}))
+
+	return key, nil
+}
+
+func jwkPublicKey(document documents.TokenKeyResponse) (interface{}, error) {
+	switch document.Kty {
+	case "RSA":
+		n, err := jwkInt(document.Kid, "n", document.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := jwkInt(document.Kid, "e", document.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch document.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("key %q has an unsupported curve: %q", document.Kid, document.Crv)
+		}
+
+		x, err := jwkInt(document.Kid, "x", document.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := jwkInt(document.Kid, "y", document.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("key %q is not a point on the %s curve", document.Kid, document.Crv)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if document.Crv != "Ed25519" {
+			return nil, fmt.Errorf("key %q has an unsupported curve: %q", document.Kid, document.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(document.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("key %q has an invalid \"x\" parameter", document.Kid)
+		}
+
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, nil
+	}
+}
+
+// jwkAlgorithm returns the algorithm implied by the type and curve of a key
+// that does not name its algorithm. RSA keys may be used with several
+// algorithms, so none is implied for them.
+func jwkAlgorithm(document documents.TokenKeyResponse) string {
+	switch {
+	case document.Kty == "EC" && document.Crv == "P-256":
+		return "ES256"
+	case document.Kty == "EC" && document.Crv == "P-384":
+		return "ES384"
+	case document.Kty == "EC" && document.Crv == "P-521":
+		return "ES512"
+	case document.Kty == "OKP" && document.Crv == "Ed25519":
+		return "EdDSA"
+	default:
+		return ""
+	}
+}
+
+func jwkInt(kid, name, value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("key %q has an invalid %q parameter", kid, name)
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,219 @@
+package warrant
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// Page describes the position of a single page of results within the full
+// set of resources matching a list query.
+type Page struct {
+	// StartIndex is the 1-based index of the first resource in the page.
+	StartIndex int
+
+	// ItemsPerPage is the maximum number of resources UAA will return in a
+	// single page.
+	ItemsPerPage int
+
+	// TotalResults is the total number of resources matching the query,
+	// across all pages.
+	TotalResults int
+}
+
+func newPage(startIndex, itemsPerPage, totalResults int) Page {
+	return Page{
+		StartIndex:   startIndex,
+		ItemsPerPage: itemsPerPage,
+		TotalResults: totalResults,
+	}
+}
+
+func (q Query) toValues() url.Values {
+	values := url.Values{
+		"filter": []string{And(RawFilter(q.Filter), q.Where).String()},
+		"sortBy": []string{q.SortBy},
+	}
+
+	if q.StartIndex > 0 {
+		values.Set("startIndex", strconv.Itoa(q.StartIndex))
+	}
+
+	if q.Count > 0 {
+		values.Set("count", strconv.Itoa(q.Count))
+	}
+
+	return values
+}
+
+// pager tracks the position of an iterator as it walks the pages of a
+// list query.
+type pager struct {
+	ctx   context.Context
+	query Query
+	done  bool
+	err   error
+}
+
+func newPager(ctx context.Context, query Query) pager {
+	if query.StartIndex < 1 {
+		query.StartIndex = 1
+	}
+
+	return pager{
+		ctx:   ctx,
+		query: query,
+	}
+}
+
+// advance moves the pager past a page containing the given number of
+// resources, marking it done once every resource has been seen.
+func (p *pager) advance(page Page, count int) {
+	next := p.query.StartIndex + count
+	if count == 0 || next > page.TotalResults {
+		p.done = true
+		return
+	}
+
+	p.query.StartIndex = next
+}
+
+// fail records the error that stopped iteration.
+func (p *pager) fail(err error) {
+	p.err = err
+	p.done = true
+}
+
+// UsersIterator walks the user resources matching a query, fetching
+// additional pages from UAA as they are needed. Its usage follows that
+// of bufio.Scanner:
+//
+//	users := w.Users.Iterate(warrant.Query{}, token)
+//	for users.Next() {
+//		log.Println(users.User().UserName)
+//	}
+//	if err := users.Err(); err != nil {
+//		log.Fatal(err)
+//	}
+type UsersIterator struct {
+	pager pager
+	list  func(context.Context, Query) ([]User, Page, error)
+	users []User
+	user  User
+}
+
+// Next advances the iterator to the next user, returning false when there
+// are no more users or an error has occurred.
+func (i *UsersIterator) Next() bool {
+	for len(i.users) == 0 {
+		if i.pager.done {
+			return false
+		}
+
+		users, page, err := i.list(i.pager.ctx, i.pager.query)
+		if err != nil {
+			i.pager.fail(err)
+			return false
+		}
+
+		i.users = users
+		i.pager.advance(page, len(users))
+	}
+
+	i.user, i.users = i.users[0], i.users[1:]
+	return true
+}
+
+// User returns the user at the current position of the iterator.
+func (i *UsersIterator) User() User {
+	return i.user
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (i *UsersIterator) Err() error {
+	return i.pager.err
+}
+
+// GroupsIterator walks the group resources matching a query, fetching
+// additional pages from UAA as they are needed. It is used in the same
+// manner as UsersIterator.
+type GroupsIterator struct {
+	pager  pager
+	list   func(context.Context, Query) ([]Group, Page, error)
+	groups []Group
+	group  Group
+}
+
+// Next advances the iterator to the next group, returning false when there
+// are no more groups or an error has occurred.
+func (i *GroupsIterator) Next() bool {
+	for len(i.groups) == 0 {
+		if i.pager.done {
+			return false
+		}
+
+		groups, page, err := i.list(i.pager.ctx, i.pager.query)
+		if err != nil {
+			i.pager.fail(err)
+			return false
+		}
+
+		i.groups = groups
+		i.pager.advance(page, len(groups))
+	}
+
+	i.group, i.groups = i.groups[0], i.groups[1:]
+	return true
+}
+
+// Group returns the group at the current position of the iterator.
+func (i *GroupsIterator) Group() Group {
+	return i.group
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (i *GroupsIterator) Err() error {
+	return i.pager.err
+}
+
+// ClientsIterator walks the client resources matching a query, fetching
+// additional pages from UAA as they are needed. It is used in the same
+// manner as UsersIterator.
+type ClientsIterator struct {
+	pager   pager
+	list    func(context.Context, Query) ([]Client, Page, error)
+	clients []Client
+	client  Client
+}
+
+// Next advances the iterator to the next client, returning false when there
+// are no more clients or an error has occurred.
+func (i *ClientsIterator) Next() bool {
+	for len(i.clients) == 0 {
+		if i.pager.done {
+			return false
+		}
+
+		clients, page, err := i.list(i.pager.ctx, i.pager.query)
+		if err != nil {
+			i.pager.fail(err)
+			return false
+		}
+
+		i.clients = clients
+		i.pager.advance(page, len(clients))
+	}
+
+	i.client, i.clients = i.clients[0], i.clients[1:]
+	return true
+}
+
+// Client returns the client at the current position of the iterator.
+func (i *ClientsIterator) Client() Client {
+	return i.client
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (i *ClientsIterator) Err() error {
+	return i.pager.err
+}
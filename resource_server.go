@@ -0,0 +1,150 @@
+package warrant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type tokenContextKey struct{}
+
+// SigningKeySource supplies the signing keys used to verify tokens.
+type SigningKeySource interface {
+	// SigningKeys returns the signing keys that may be used to verify a token
+	// signed by the key with the given ID.
+	SigningKeys(ctx context.Context, keyID string) ([]SigningKey, error)
+}
+
+// ResourceServer protects HTTP handlers so that they can only be reached by
+// requests carrying a valid UAA bearer token in their Authorization header.
+type ResourceServer struct {
+	// Validation describes the checks made against the claims of every token,
+	// such as the expected issuer and the allowed clock skew.
+	Validation TokenValidation
+
+	// SigningKeySource supplies the keys used to verify the signature of each
+	// token. When this value is nil, the keys are retrieved from UAA for every
+	// request.
+	SigningKeySource SigningKeySource
+
+	tokens TokensService
+}
+
+// NewResourceServer returns a ResourceServer initialized with the given Config.
+func NewResourceServer(config Config) ResourceServer {
+	return ResourceServer{
+		tokens: NewTokensService(config),
+	}
+}
+
+// Protect returns an http.Handler that passes requests on to the given handler
+// only when they carry a token that has been signed by UAA and satisfies the
+// Validation of the ResourceServer. The Scopes and Audiences of the given
+// TokenValidation are required in addition to those of the ResourceServer, and
+// so can be used to specify the requirements of an individual route.
+//
+// Requests without a valid token receive a 401 response. Requests with a token
+// lacking a required audience receive a 403 access_denied response, and those
+// lacking a required scope a 403 insufficient_scope response, as they do from
+// UAA itself. Each response has an OAuth error body. The decoded token can be retrieved by the handler using
+// TokenFromContext.
+func (rs ResourceServer) Protect(handler http.Handler, route TokenValidation) http.Handler {
+	validation := rs.Validation
+	validation.Scopes = append(append([]string{}, rs.Validation.Scopes...), route.Scopes...)
+	validation.Audiences = append(append([]string{}, rs.Validation.Audiences...), route.Audiences...)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		authorization := req.Header.Get("Authorization")
+		if authorization == "" {
+			writeOAuthError(w, http.StatusUnauthorized, "unauthorized", "Full authentication is required to access this resource")
+			return
+		}
+
+		fields := strings.Fields(authorization)
+		if len(fields) != 2 || !strings.EqualFold(fields[0], "bearer") {
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_token", "Authorization header is not a bearer token")
+			return
+		}
+
+		token, err := rs.tokens.Decode(fields[1])
+		if err != nil {
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_token", err.Error())
+			return
+		}
+
+		signingKeys, err := rs.signingKeys(ctx, token.KeyID)
+		if err != nil {
+			writeOAuthError(w, http.StatusInternalServerError, "server_error", "signing keys could not be retrieved")
+			return
+		}
+
+		err = token.Verify(signingKeys)
+		if err != nil {
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_token", err.Error())
+			return
+		}
+
+		err = token.Validate(validation)
+		if err != nil {
+			switch err.(type) {
+			case TokenAudienceError:
+				writeOAuthError(w, http.StatusForbidden, "access_denied", err.Error())
+				return
+			case TokenScopeError:
+				writeOAuthError(w, http.StatusForbidden, "insufficient_scope", err.Error())
+				return
+			}
+
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_token", err.Error())
+			return
+		}
+
+		handler.ServeHTTP(w, req.WithContext(NewContextWithToken(ctx, token)))
+	})
+}
+
+func (rs ResourceServer) signingKeys(ctx context.Context, keyID string) ([]SigningKey, error) {
+	if rs.SigningKeySource != nil {
+		return rs.SigningKeySource.SigningKeys(ctx, keyID)
+	}
+
+	return rs.tokens.GetSigningKeysWithContext(ctx)
+}
+
+// NewContextWithToken returns a copy of the given context that carries the given token.
+func NewContextWithToken(ctx context.Context, token Token) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, token)
+}
+
+// TokenFromContext returns the token carried by the given context, if any. The
+// handlers protected by a ResourceServer receive requests whose context carries
+// the verified token.
+func TokenFromContext(ctx context.Context) (Token, bool) {
+	token, ok := ctx.Value(tokenContextKey{}).(Token)
+	return token, ok
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, errorCode, description string) {
+	switch {
+	case errorCode == "unauthorized":
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer error=%q, error_description=%q", errorCode, description))
+	}
+
+	response, err := json.Marshal(map[string]string{
+		"error":             errorCode,
+		"error_description": description,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(response)
+}
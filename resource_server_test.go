@@ -0,0 +1,205 @@
+package warrant_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pivotal-cf-experimental/warrant"
+	"github.com/pivotal-cf-experimental/warrant/internal/server/common"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeSigningKeySource struct {
+	keyIDs []string
+	err    error
+}
+
+func (s *fakeSigningKeySource) SigningKeys(ctx context.Context, keyID string) ([]warrant.SigningKey, error) {
+	s.keyIDs = append(s.keyIDs, keyID)
+	return nil, s.err
+}
+
+var _ = Describe("ResourceServer", func() {
+	var (
+		resourceServer warrant.ResourceServer
+		handler        http.Handler
+		recorder       *httptest.ResponseRecorder
+		request        *http.Request
+		receivedToken  warrant.Token
+	)
+
+	BeforeEach(func() {
+		resourceServer = warrant.NewResourceServer(warrant.Config{
+			Host:          fakeUAA.URL(),
+			SkipVerifySSL: true,
+			TraceWriter:   TraceWriter,
+		})
+
+		receivedToken = warrant.Token{}
+		handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var ok bool
+			receivedToken, ok = warrant.TokenFromContext(req.Context())
+			Expect(ok).To(BeTrue())
+
+			w.WriteHeader(http.StatusTeapot)
+		})
+
+		recorder = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "/protected", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	oauthError := func() map[string]string {
+		var body map[string]string
+		err := json.Unmarshal(recorder.Body.Bytes(), &body)
+		Expect(err).NotTo(HaveOccurred())
+		return body
+	}
+
+	It("passes requests with a valid token to the handler", func() {
+		request.Header.Set("Authorization", "Bearer "+fakeUAA.UserTokenFor("some-user-id", []string{"bananas.eat", "openid"}, []string{"bananas"}))
+
+		resourceServer.Protect(handler, warrant.TokenValidation{
+			Scopes:    []string{"bananas.eat"},
+			Audiences: []string{"bananas"},
+		}).ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).To(Equal(http.StatusTeapot))
+		Expect(receivedToken.UserID).To(Equal("some-user-id"))
+		Expect(receivedToken.Scopes).To(Equal([]string{"bananas.eat", "openid"}))
+	})
+
+	It("uses the given signing key source to retrieve keys", func() {
+		source := &fakeSigningKeySource{err: errors.New("keys unavailable")}
+		resourceServer.SigningKeySource = source
+		request.Header.Set("Authorization", "Bearer "+fakeUAA.UserTokenFor("some-user-id", []string{}, []string{}))
+
+		resourceServer.Protect(handler, warrant.TokenValidation{}).ServeHTTP(recorder, request)
+
+		Expect(source.keyIDs).To(Equal([]string{"legacy-token-key"}))
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(oauthError()["error"]).To(Equal("server_error"))
+	})
+
+	Context("failure cases", func() {
+		It("responds with a 401 when the Authorization header is missing", func() {
+			resourceServer.Protect(handler, warrant.TokenValidation{}).ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(recorder.Header().Get("WWW-Authenticate")).To(Equal("Bearer"))
+			Expect(oauthError()).To(Equal(map[string]string{
+				"error":             "unauthorized",
+				"error_description": "Full authentication is required to access this resource",
+			}))
+		})
+
+		It("responds with a 401 when the Authorization header is not a bearer token", func() {
+			request.SetBasicAuth("username", "password")
+
+			resourceServer.Protect(handler, warrant.TokenValidation{}).ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(oauthError()["error"]).To(Equal("invalid_token"))
+		})
+
+		It("responds with a 401 when the token cannot be decoded", func() {
+			request.Header.Set("Authorization", "Bearer not-a-token")
+
+			resourceServer.Protect(handler, warrant.TokenValidation{}).ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(recorder.Header().Get("WWW-Authenticate")).To(Equal(`Bearer error="invalid_token", error_description="invalid number of segments in token (1/3)"`))
+			Expect(oauthError()).To(Equal(map[string]string{
+				"error":             "invalid_token",
+				"error_description": "invalid number of segments in token (1/3)",
+			}))
+		})
+
+		It("responds with a 401 when the token is signed using HMAC with the public key", func() {
+			unsignedToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"jti":       "some-token-id",
+				"client_id": "some-client-id",
+				"user_id":   "some-user-id",
+				"scope":     []string{"uaa.admin"},
+				"exp":       time.Now().Add(time.Hour).Unix(),
+			})
+			unsignedToken.Header["kid"] = "legacy-token-key"
+
+			token, err := unsignedToken.SignedString([]byte(common.TestPublicKey))
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			resourceServer.Protect(handler, warrant.TokenValidation{}).ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(oauthError()["error"]).To(Equal("invalid_token"))
+		})
+
+		It("responds with a 401 when the token signature is invalid", func() {
+			token := fakeUAA.UserTokenFor("some-user-id", []string{}, []string{})
+			request.Header.Set("Authorization", "Bearer "+token[:len(token)-4]+"AAAA")
+
+			resourceServer.Protect(handler, warrant.TokenValidation{}).ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(oauthError()["error"]).To(Equal("invalid_token"))
+		})
+
+		It("responds with a 401 when the token has expired", func() {
+			resourceServer.Validation.Time = time.Now().Add(24 * time.Hour)
+			token, err := warrant.NewClientsService(warrant.Config{
+				Host:          fakeUAA.URL(),
+				SkipVerifySSL: true,
+				TraceWriter:   TraceWriter,
+			}).GetToken("admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			resourceServer.Protect(handler, warrant.TokenValidation{}).ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(oauthError()["error"]).To(Equal("invalid_token"))
+			Expect(oauthError()["error_description"]).To(ContainSubstring("token expired at"))
+		})
+
+		It("responds with a 403 when the token is missing a required audience", func() {
+			request.Header.Set("Authorization", "Bearer "+fakeUAA.UserTokenFor("some-user-id", []string{"bananas.eat"}, []string{"apples"}))
+
+			resourceServer.Protect(handler, warrant.TokenValidation{
+				Audiences: []string{"bananas"},
+			}).ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusForbidden))
+			Expect(recorder.Header().Get("WWW-Authenticate")).To(ContainSubstring(`error="access_denied"`))
+			Expect(oauthError()).To(Equal(map[string]string{
+				"error":             "access_denied",
+				"error_description": `token is missing required audience "bananas"`,
+			}))
+		})
+
+		It("responds with a 403 when the token is missing a required scope", func() {
+			resourceServer.Validation.Scopes = []string{"openid"}
+			request.Header.Set("Authorization", "Bearer "+fakeUAA.UserTokenFor("some-user-id", []string{"openid"}, []string{}))
+
+			resourceServer.Protect(handler, warrant.TokenValidation{
+				Scopes: []string{"bananas.eat"},
+			}).ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusForbidden))
+			Expect(recorder.Header().Get("WWW-Authenticate")).To(ContainSubstring(`error="insufficient_scope"`))
+			Expect(oauthError()).To(Equal(map[string]string{
+				"error":             "insufficient_scope",
+				"error_description": `token is missing required scope "bananas.eat"`,
+			}))
+		})
+	})
+})
@@ -0,0 +1,51 @@
+package warrant
+
+import (
+	"time"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/network"
+)
+
+// RetryPolicy describes how requests to UAA are retried when they fail with
+// a transient error, such as a reset connection or a 503 response from a
+// router in front of the UAA service. The zero value makes a single attempt
+// of every request.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is attempted,
+	// including the first attempt. Requests are not retried when this value
+	// is less than 2.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry, which defaults to
+	// 100 milliseconds. The delay doubles for each subsequent retry, and is
+	// reduced by a random amount of up to half so that the retries of
+	// concurrent requests are spread out.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the longest delay between attempts, which defaults to 10
+	// seconds. When a response includes a Retry-After header, the request is
+	// retried after the delay it asks for, unless that delay is longer than
+	// MaxBackoff, in which case the request is not retried.
+	MaxBackoff time.Duration
+
+	// RetryableStatusCodes are the response status codes that cause a
+	// request to be retried. This value defaults to 429, 502, 503, and 504.
+	RetryableStatusCodes []int
+
+	// RetryableMethods are the HTTP methods of the requests that may be
+	// retried. This value defaults to the idempotent methods GET, HEAD,
+	// OPTIONS, PUT, and DELETE. Requests that use POST, including those that
+	// create resources and fetch tokens, are only retried when "POST" is
+	// included explicitly, as retrying them may repeat their effects.
+	RetryableMethods []string
+}
+
+func (p RetryPolicy) networkRetryPolicy() network.RetryPolicy {
+	return network.RetryPolicy{
+		MaxAttempts:          p.MaxAttempts,
+		InitialBackoff:       p.InitialBackoff,
+		MaxBackoff:           p.MaxBackoff,
+		RetryableStatusCodes: p.RetryableStatusCodes,
+		RetryableMethods:     p.RetryableMethods,
+	}
+}
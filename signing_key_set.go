@@ -0,0 +1,113 @@
+package warrant
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSigningKeyRefreshInterval is the default amount of time a
+	// SigningKeySet caches the signing keys retrieved from UAA.
+	DefaultSigningKeyRefreshInterval = time.Hour
+
+	// DefaultSigningKeyMinRefreshInterval is the default minimum amount of time
+	// between the requests a SigningKeySet makes to UAA when it encounters
+	// tokens signed by unknown keys.
+	DefaultSigningKeyMinRefreshInterval = 30 * time.Second
+)
+
+// SigningKeySet is a cache of the signing keys used by UAA to sign tokens. The
+// keys are retrieved from UAA when they are first needed, and refreshed once
+// they are older than the RefreshInterval or when a token signed by an unknown
+// key is encountered. A SigningKeySet is a SigningKeySource, and so can be used
+// to verify the tokens received by a ResourceServer. It is safe for concurrent
+// use by multiple goroutines.
+type SigningKeySet struct {
+	// RefreshInterval is the amount of time the signing keys are cached before
+	// they are refreshed. This value defaults to DefaultSigningKeyRefreshInterval.
+	RefreshInterval time.Duration
+
+	// MinRefreshInterval is the minimum amount of time between refreshes of the
+	// signing keys made because a token was signed by an unknown key. This
+	// limits the number of requests made to UAA by callers presenting tokens
+	// with made up key IDs. This value defaults to DefaultSigningKeyMinRefreshInterval.
+	MinRefreshInterval time.Duration
+
+	tokens    TokensService
+	mutex     sync.Mutex
+	keys      []SigningKey
+	err       error
+	fetchedAt time.Time
+}
+
+// NewSigningKeySet returns a SigningKeySet that retrieves signing keys from the
+// UAA identified by the given Config.
+func NewSigningKeySet(config Config) *SigningKeySet {
+	return &SigningKeySet{
+		RefreshInterval:    DefaultSigningKeyRefreshInterval,
+		MinRefreshInterval: DefaultSigningKeyMinRefreshInterval,
+		tokens:             NewTokensService(config),
+	}
+}
+
+// SigningKeys returns the cached signing keys, refreshing them first if they
+// are stale or if none of them has the given key ID. Should a refresh fail,
+// the previously cached keys are returned so that verification can continue
+// while UAA is unavailable; an error is returned only when no keys have ever
+// been retrieved. Failed retrievals are subject to the same rate limiting as
+// refreshes for unknown key IDs.
+func (s *SigningKeySet) SigningKeys(ctx context.Context, keyID string) ([]SigningKey, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.needsRefresh(keyID) {
+		keys, err := s.tokens.GetSigningKeysWithContext(ctx)
+		if err == nil {
+			s.keys = keys
+		}
+
+		s.err = err
+		s.fetchedAt = time.Now()
+	}
+
+	if s.keys == nil {
+		return nil, s.err
+	}
+
+	return s.keys, nil
+}
+
+// Verify verifies the authenticity of the given token using the signing keys
+// in the set.
+func (s *SigningKeySet) Verify(ctx context.Context, token Token) error {
+	keys, err := s.SigningKeys(ctx, token.KeyID)
+	if err != nil {
+		return err
+	}
+
+	return token.Verify(keys)
+}
+
+func (s *SigningKeySet) needsRefresh(keyID string) bool {
+	if s.fetchedAt.IsZero() {
+		return true
+	}
+
+	age := time.Since(s.fetchedAt)
+	if age >= s.RefreshInterval {
+		return true
+	}
+
+	if age < s.MinRefreshInterval {
+		return false
+	}
+
+	for _, key := range s.keys {
+		if key.KeyId == keyID {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,194 @@
+package warrant_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pivotal-cf-experimental/warrant"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type tokenKeysServer struct {
+	mutex    sync.Mutex
+	keyIDs   []string
+	requests int
+	failing  bool
+}
+
+func (s *tokenKeysServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requests++
+	if s.failing {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	keys := []string{}
+	for _, keyID := range s.keyIDs {
+		keys = append(keys, fmt.Sprintf(`{"kid":%q,"alg":"HS256","value":"secret-%s"}`, keyID, keyID))
+	}
+
+	fmt.Fprintf(w, `{"keys":[%s]}`, strings.Join(keys, ","))
+}
+
+func (s *tokenKeysServer) setKeyIDs(keyIDs ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.keyIDs = keyIDs
+}
+
+func (s *tokenKeysServer) setFailing(failing bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.failing = failing
+}
+
+func (s *tokenKeysServer) requestCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.requests
+}
+
+var _ = Describe("SigningKeySet", func() {
+	var (
+		keysServer *tokenKeysServer
+		server     *httptest.Server
+		keySet     *warrant.SigningKeySet
+		service    warrant.TokensService
+	)
+
+	signToken := func(keyID string) warrant.Token {
+		unsignedToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"client_id": "some-client-id",
+		})
+		unsignedToken.Header["kid"] = keyID
+
+		signedToken, err := unsignedToken.SignedString([]byte("secret-" + keyID))
+		Expect(err).NotTo(HaveOccurred())
+
+		token, err := service.Decode(signedToken)
+		Expect(err).NotTo(HaveOccurred())
+
+		return token
+	}
+
+	BeforeEach(func() {
+		keysServer = &tokenKeysServer{keyIDs: []string{"key-1"}}
+		server = httptest.NewServer(keysServer)
+
+		config := warrant.Config{
+			Host:        server.URL,
+			TraceWriter: TraceWriter,
+		}
+		keySet = warrant.NewSigningKeySet(config)
+		service = warrant.NewTokensService(config)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("verifies tokens using the cached keys", func() {
+		Expect(keySet.Verify(context.Background(), signToken("key-1"))).To(Succeed())
+		Expect(keySet.Verify(context.Background(), signToken("key-1"))).To(Succeed())
+
+		Expect(keysServer.requestCount()).To(Equal(1))
+	})
+
+	It("refreshes the keys once they are older than the refresh interval", func() {
+		keySet.RefreshInterval = 50 * time.Millisecond
+
+		keys, err := keySet.SigningKeys(context.Background(), "key-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(HaveLen(1))
+
+		keysServer.setKeyIDs("key-1", "key-2")
+		time.Sleep(100 * time.Millisecond)
+
+		keys, err = keySet.SigningKeys(context.Background(), "key-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(HaveLen(2))
+		Expect(keysServer.requestCount()).To(Equal(2))
+	})
+
+	It("refreshes the keys when a token is signed by an unknown key", func() {
+		keySet.MinRefreshInterval = 0
+
+		Expect(keySet.Verify(context.Background(), signToken("key-1"))).To(Succeed())
+
+		keysServer.setKeyIDs("key-1", "key-2")
+		Expect(keySet.Verify(context.Background(), signToken("key-2"))).To(Succeed())
+		Expect(keysServer.requestCount()).To(Equal(2))
+	})
+
+	It("rate limits the refreshes made for unknown keys", func() {
+		Expect(keySet.Verify(context.Background(), signToken("key-1"))).To(Succeed())
+
+		for i := 0; i < 5; i++ {
+			err := keySet.Verify(context.Background(), signToken("unknown-key"))
+			Expect(err).To(MatchError("token was not signed by a known key"))
+		}
+
+		Expect(keysServer.requestCount()).To(Equal(1))
+	})
+
+	It("continues to use the cached keys when a refresh fails", func() {
+		keySet.MinRefreshInterval = 0
+
+		Expect(keySet.Verify(context.Background(), signToken("key-1"))).To(Succeed())
+
+		keysServer.setFailing(true)
+		err := keySet.Verify(context.Background(), signToken("unknown-key"))
+		Expect(err).To(MatchError("token was not signed by a known key"))
+
+		Expect(keySet.Verify(context.Background(), signToken("key-1"))).To(Succeed())
+	})
+
+	It("can be used as the signing key source of a resource server", func() {
+		resourceServer := warrant.NewResourceServer(warrant.Config{Host: server.URL})
+		resourceServer.SigningKeySource = keySet
+
+		token := signToken("key-1")
+		handler := resourceServer.Protect(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}), warrant.TokenValidation{})
+
+		for i := 0; i < 3; i++ {
+			request, err := http.NewRequest("GET", "/", nil)
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set("Authorization", fmt.Sprintf("Bearer %s.%s.%s", token.Segments.Header, token.Segments.Claims, token.Segments.Signature))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		}
+
+		Expect(keysServer.requestCount()).To(Equal(1))
+	})
+
+	Context("failure cases", func() {
+		It("returns an error when the keys have never been retrieved", func() {
+			keysServer.setFailing(true)
+
+			_, err := keySet.SigningKeys(context.Background(), "key-1")
+			Expect(err).To(BeAssignableToTypeOf(warrant.UnexpectedStatusError{}))
+
+			_, err = keySet.SigningKeys(context.Background(), "key-1")
+			Expect(err).To(BeAssignableToTypeOf(warrant.UnexpectedStatusError{}))
+			Expect(keysServer.requestCount()).To(Equal(1))
+		})
+	})
+})
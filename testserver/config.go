@@ -0,0 +1,55 @@
+package testserver
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+// Config holds the settings used by NewUAAWithConfig to initialize a UAA.
+// Any fields left with their zero value fall back to the defaults used by
+// NewUAA.
+type Config struct {
+	// SigningKey is the PEM encoded private key used to sign tokens, which
+	// may be any of the kinds of key accepted by UAA.SetSigningKey. The
+	// corresponding public key is published by the /token_key and
+	// /token_keys endpoints. An RSA key shared by all servers is used when
+	// none is given.
+	SigningKey string
+
+	// SigningAlgorithm is the JSON Web Algorithm used to sign tokens, such
+	// as "RS256", "PS384", "ES256", or "EdDSA". This value defaults to the
+	// algorithm suited to the SigningKey.
+	SigningAlgorithm string
+
+	// KeyID is the value of the "kid" header included in the tokens
+	// signed by the server. This value defaults to "legacy-token-key".
+	KeyID string
+
+	// Issuer is the value of the "iss" claim included in the tokens
+	// granted to clients. This value defaults to the token endpoint of
+	// the server, as in "https://localhost:1234/oauth/token".
+	Issuer string
+
+	// DefaultScopes are the scopes that users may be granted through the
+	// /oauth/authorize endpoint.
+	DefaultScopes []string
+
+	// AccessTokenValidity is how long access tokens are valid for after
+	// they are granted. This value defaults to 5000 seconds.
+	AccessTokenValidity time.Duration
+
+	// RefreshTokenValidity is how long refresh tokens are valid for after
+	// they are granted. Refresh tokens do not expire when this is zero.
+	RefreshTokenValidity time.Duration
+
+	// AdminClientID and AdminClientSecret are the credentials of the
+	// client that exists in the server from the start, and survives
+	// calls to Reset. They default to "admin" and "admin".
+	AdminClientID     string
+	AdminClientSecret string
+
+	// TLS causes the server to be started with TLS using the given
+	// configuration. The server uses a self-signed certificate when the
+	// configuration does not contain any certificates.
+	TLS *tls.Config
+}
@@ -0,0 +1,299 @@
+package testserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/documents"
+	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Fixtures describes a set of resources that can be seeded directly
+// into the server using Seed or SeedFile. Fixtures can be written in
+// Go or loaded from a YAML or JSON file such as:
+//
+//	users:
+//	- userName: alice
+//	  password: password
+//	  emails: [alice@example.com]
+//	groups:
+//	- displayName: scim.read
+//	  members: [alice]
+//	clients:
+//	- client_id: app
+//	  client_secret: secret
+//	  authorized_grant_types: [client_credentials]
+//	  authorities: [scim.read]
+type Fixtures struct {
+	Users   []UserFixture   `json:"users" yaml:"users"`
+	Groups  []GroupFixture  `json:"groups" yaml:"groups"`
+	Clients []ClientFixture `json:"clients" yaml:"clients"`
+}
+
+// UserFixture describes a user to be seeded into the server.
+type UserFixture struct {
+	// ID is the unique identifier of the user. One is generated
+	// when it is not given.
+	ID         string   `json:"id" yaml:"id"`
+	UserName   string   `json:"userName" yaml:"userName"`
+	Password   string   `json:"password" yaml:"password"`
+	Emails     []string `json:"emails" yaml:"emails"`
+	GivenName  string   `json:"givenName" yaml:"givenName"`
+	FamilyName string   `json:"familyName" yaml:"familyName"`
+	Verified   bool     `json:"verified" yaml:"verified"`
+
+	// Origin is the identity provider of the user. This value
+	// defaults to "uaa".
+	Origin string `json:"origin" yaml:"origin"`
+}
+
+// GroupFixture describes a group to be seeded into the server.
+type GroupFixture struct {
+	// ID is the unique identifier of the group. One is generated
+	// when it is not given.
+	ID          string `json:"id" yaml:"id"`
+	DisplayName string `json:"displayName" yaml:"displayName"`
+	Description string `json:"description" yaml:"description"`
+
+	// Members are the user names, or ids, of the users that
+	// belong to the group. They may refer to users seeded by the
+	// same Fixtures or to users already held by the server.
+	Members []string `json:"members" yaml:"members"`
+}
+
+// ClientFixture describes a client to be seeded into the server.
+type ClientFixture struct {
+	ID                   string   `json:"client_id" yaml:"client_id"`
+	Secret               string   `json:"client_secret" yaml:"client_secret"`
+	Name                 string   `json:"name" yaml:"name"`
+	Scope                []string `json:"scope" yaml:"scope"`
+	ResourceIDs          []string `json:"resource_ids" yaml:"resource_ids"`
+	Authorities          []string `json:"authorities" yaml:"authorities"`
+	AuthorizedGrantTypes []string `json:"authorized_grant_types" yaml:"authorized_grant_types"`
+	AccessTokenValidity  int      `json:"access_token_validity" yaml:"access_token_validity"`
+	RedirectURI          []string `json:"redirect_uri" yaml:"redirect_uri"`
+	Autoapprove          []string `json:"autoapprove" yaml:"autoapprove"`
+}
+
+// Seed adds the users, groups, and clients described by the given
+// fixtures directly to the server without making any HTTP requests.
+// No resources are added if any of the fixtures are invalid or share
+// a name or id with another fixture or a resource held by the server.
+func (s *UAA) Seed(fixtures Fixtures) error {
+	users := map[string]domain.User{}
+	userIDs := map[string]bool{}
+	var userList []domain.User
+	for _, fixture := range fixtures.Users {
+		user, err := s.seedUser(fixture)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := users[user.UserName]; ok {
+			return fmt.Errorf("username already in use: %s", user.UserName)
+		}
+
+		if userIDs[user.ID] {
+			return fmt.Errorf("user id already in use: %s", user.ID)
+		}
+		userIDs[user.ID] = true
+
+		users[user.UserName] = user
+		userList = append(userList, user)
+	}
+
+	clientIDs := map[string]bool{}
+	var clientList []domain.Client
+	for _, fixture := range fixtures.Clients {
+		client, err := seedClient(fixture)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := s.clients.Get(client.ID); ok || clientIDs[client.ID] {
+			return fmt.Errorf("client already exists: %s", client.ID)
+		}
+		clientIDs[client.ID] = true
+
+		clientList = append(clientList, client)
+	}
+
+	groupList, err := s.seedGroups(fixtures.Groups, users)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range userList {
+		s.users.Add(user)
+	}
+
+	for _, client := range clientList {
+		s.clients.Add(client)
+	}
+
+	for _, group := range groupList {
+		s.groups.Add(group)
+	}
+
+	return nil
+}
+
+// SeedFile seeds the server with the fixtures in the file at the
+// given path. Files with a ".yml" or ".yaml" extension are parsed as
+// YAML, all others as JSON.
+func (s *UAA) SeedFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var fixtures Fixtures
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		err = yaml.UnmarshalStrict(data, &fixtures)
+	default:
+		err = json.Unmarshal(data, &fixtures)
+	}
+	if err != nil {
+		return fmt.Errorf("could not parse fixtures from %s: %s", path, err)
+	}
+
+	return s.Seed(fixtures)
+}
+
+func (s *UAA) seedUser(fixture UserFixture) (domain.User, error) {
+	if fixture.UserName == "" {
+		return domain.User{}, fmt.Errorf("user fixture is missing a userName")
+	}
+
+	if _, ok := s.users.GetByName(fixture.UserName); ok {
+		return domain.User{}, fmt.Errorf("username already in use: %s", fixture.UserName)
+	}
+
+	if fixture.ID != "" {
+		if _, ok := s.users.Get(fixture.ID); ok {
+			return domain.User{}, fmt.Errorf("user id already in use: %s", fixture.ID)
+		}
+	}
+
+	var emails []documents.Email
+	for _, email := range fixture.Emails {
+		emails = append(emails, documents.Email{Value: email})
+	}
+
+	user := domain.NewUserFromCreateDocument(documents.CreateUserRequest{
+		UserName: fixture.UserName,
+		Emails:   emails,
+	})
+	if fixture.ID != "" {
+		user.ID = fixture.ID
+	}
+	if fixture.Origin != "" {
+		user.Origin = fixture.Origin
+	}
+	user.GivenName = fixture.GivenName
+	user.FamilyName = fixture.FamilyName
+	user.Password = fixture.Password
+	user.Verified = fixture.Verified
+
+	return user, nil
+}
+
+func (s *UAA) seedGroups(fixtures []GroupFixture, users map[string]domain.User) (domain.GroupsList, error) {
+	var groups domain.GroupsList
+	names := map[string]bool{}
+	ids := map[string]bool{}
+	for _, fixture := range fixtures {
+		if fixture.DisplayName == "" {
+			return nil, fmt.Errorf("group fixture is missing a displayName")
+		}
+
+		if _, ok := s.groups.GetByName(fixture.DisplayName); ok || names[fixture.DisplayName] {
+			return nil, fmt.Errorf("group already exists: %s", fixture.DisplayName)
+		}
+		names[fixture.DisplayName] = true
+
+		if fixture.ID != "" {
+			if _, ok := s.groups.Get(fixture.ID); ok || ids[fixture.ID] {
+				return nil, fmt.Errorf("group id already in use: %s", fixture.ID)
+			}
+			ids[fixture.ID] = true
+		}
+
+		var members []documents.CreateMemberRequest
+		for _, name := range fixture.Members {
+			user, ok := findSeededUser(users, name)
+			if !ok {
+				user, ok = s.users.GetByName(name)
+			}
+			if !ok {
+				user, ok = s.users.Get(name)
+			}
+			if !ok {
+				return nil, fmt.Errorf("member %q of group %q does not exist", name, fixture.DisplayName)
+			}
+
+			members = append(members, documents.CreateMemberRequest{
+				Origin: user.Origin,
+				Type:   "USER",
+				Value:  user.ID,
+			})
+		}
+
+		group := domain.NewGroupFromCreateDocument(documents.CreateGroupRequest{
+			DisplayName: fixture.DisplayName,
+			Description: fixture.Description,
+			Members:     members,
+		})
+		if fixture.ID != "" {
+			group.ID = fixture.ID
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func findSeededUser(users map[string]domain.User, name string) (domain.User, bool) {
+	if user, ok := users[name]; ok {
+		return user, true
+	}
+
+	for _, user := range users {
+		if user.ID == name {
+			return user, true
+		}
+	}
+
+	return domain.User{}, false
+}
+
+func seedClient(fixture ClientFixture) (domain.Client, error) {
+	if fixture.ID == "" {
+		return domain.Client{}, fmt.Errorf("client fixture is missing a client_id")
+	}
+
+	client := domain.NewClientFromDocument(documents.CreateUpdateClientRequest{
+		ClientID:             fixture.ID,
+		ClientSecret:         fixture.Secret,
+		Name:                 fixture.Name,
+		Scope:                fixture.Scope,
+		ResourceIDs:          fixture.ResourceIDs,
+		Authorities:          fixture.Authorities,
+		AuthorizedGrantTypes: fixture.AuthorizedGrantTypes,
+		AccessTokenValidity:  fixture.AccessTokenValidity,
+		RedirectURI:          fixture.RedirectURI,
+		Autoapprove:          fixture.Autoapprove,
+	})
+
+	if err := client.Validate(); err != nil {
+		return domain.Client{}, fmt.Errorf("client %q is invalid: %s", fixture.ID, err)
+	}
+
+	return client, nil
+}
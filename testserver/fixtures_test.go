@@ -0,0 +1,302 @@
+package testserver_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pivotal-cf-experimental/warrant"
+	"github.com/pivotal-cf-experimental/warrant/testserver"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fixtures", func() {
+	var (
+		uaa    *testserver.UAA
+		client warrant.Warrant
+		token  string
+		dir    string
+	)
+
+	writeFile := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		uaa = testserver.NewUAA()
+		uaa.Start()
+
+		client = warrant.New(warrant.Config{
+			Host:          uaa.URL(),
+			SkipVerifySSL: true,
+		})
+
+		var err error
+		token, err = client.Clients.GetToken("admin", "admin")
+		Expect(err).NotTo(HaveOccurred())
+
+		dir, err = ioutil.TempDir("", "fixtures")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		uaa.Close()
+		os.RemoveAll(dir)
+	})
+
+	Describe("Seed", func() {
+		It("adds the users, groups, memberships, and clients", func() {
+			err := uaa.Seed(testserver.Fixtures{
+				Users: []testserver.UserFixture{
+					{
+						ID:        "alice-id",
+						UserName:  "alice",
+						Password:  "alice-password",
+						Emails:    []string{"alice@example.com"},
+						GivenName: "Alice",
+					},
+					{
+						UserName: "bob",
+						Password: "bob-password",
+						Origin:   "ldap",
+					},
+				},
+				Groups: []testserver.GroupFixture{
+					{
+						DisplayName: "scim.read",
+						Members:     []string{"alice", "bob"},
+					},
+				},
+				Clients: []testserver.ClientFixture{
+					{
+						ID:                   "app",
+						Secret:               "app-secret",
+						Scope:                []string{"openid"},
+						Authorities:          []string{"scim.read"},
+						AuthorizedGrantTypes: []string{"client_credentials", "password"},
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			alice, err := client.Users.Get("alice-id", token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(alice.UserName).To(Equal("alice"))
+			Expect(alice.GivenName).To(Equal("Alice"))
+			Expect(alice.Emails).To(Equal([]string{"alice@example.com"}))
+			Expect(alice.Origin).To(Equal("uaa"))
+
+			users, err := client.Users.List(warrant.Query{Where: warrant.Eq("userName", "bob")}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+			Expect(users[0].Origin).To(Equal("ldap"))
+
+			_, err = client.Users.GetToken("alice", "alice-password", warrant.Client{ID: "app"})
+			Expect(err).NotTo(HaveOccurred())
+
+			groups, err := client.Groups.List(warrant.Query{Where: warrant.Eq("displayName", "scim.read")}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(groups).To(HaveLen(1))
+
+			members, err := client.Groups.ListMembers(groups[0].ID, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(HaveLen(2))
+			Expect([]string{members[0].Value, members[1].Value}).To(ConsistOf("alice-id", users[0].ID))
+
+			_, err = client.Clients.GetToken("app", "app-secret")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("allows group members to refer to users already held by the server", func() {
+			user, err := client.Users.Create("carol", "carol@example.com", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = uaa.Seed(testserver.Fixtures{
+				Groups: []testserver.GroupFixture{
+					{DisplayName: "by-name", Members: []string{"carol"}},
+					{DisplayName: "by-id", Members: []string{user.ID}},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			groups, err := client.Groups.List(warrant.Query{}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(groups).To(HaveLen(2))
+
+			for _, group := range groups {
+				_, isMember, err := client.Groups.CheckMembership(group.ID, user.ID, token)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(isMember).To(BeTrue())
+			}
+		})
+
+		Context("failure cases", func() {
+			It("does not add any resources when a fixture is invalid", func() {
+				err := uaa.Seed(testserver.Fixtures{
+					Users: []testserver.UserFixture{
+						{UserName: "alice"},
+					},
+					Groups: []testserver.GroupFixture{
+						{DisplayName: "scim.read", Members: []string{"nobody"}},
+					},
+				})
+				Expect(err).To(MatchError(`member "nobody" of group "scim.read" does not exist`))
+
+				users, err := client.Users.List(warrant.Query{}, token)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(users).To(BeEmpty())
+			})
+
+			It("returns an error when a user name is already in use", func() {
+				err := uaa.Seed(testserver.Fixtures{
+					Users: []testserver.UserFixture{
+						{UserName: "alice"},
+						{UserName: "alice"},
+					},
+				})
+				Expect(err).To(MatchError("username already in use: alice"))
+			})
+
+			It("returns an error when a user id is already in use", func() {
+				err := uaa.Seed(testserver.Fixtures{
+					Users: []testserver.UserFixture{
+						{ID: "some-user-id", UserName: "alice"},
+						{ID: "some-user-id", UserName: "bob"},
+					},
+				})
+				Expect(err).To(MatchError("user id already in use: some-user-id"))
+
+				err = uaa.Seed(testserver.Fixtures{
+					Users: []testserver.UserFixture{
+						{ID: "some-user-id", UserName: "alice"},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = uaa.Seed(testserver.Fixtures{
+					Users: []testserver.UserFixture{
+						{ID: "some-user-id", UserName: "bob"},
+					},
+				})
+				Expect(err).To(MatchError("user id already in use: some-user-id"))
+
+				users, err := client.Users.List(warrant.Query{}, token)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(users).To(HaveLen(1))
+				Expect(users[0].UserName).To(Equal("alice"))
+			})
+
+			It("returns an error when a group id is already in use", func() {
+				err := uaa.Seed(testserver.Fixtures{
+					Groups: []testserver.GroupFixture{
+						{ID: "some-group-id", DisplayName: "scim.read"},
+						{ID: "some-group-id", DisplayName: "scim.write"},
+					},
+				})
+				Expect(err).To(MatchError("group id already in use: some-group-id"))
+
+				err = uaa.Seed(testserver.Fixtures{
+					Groups: []testserver.GroupFixture{
+						{ID: "some-group-id", DisplayName: "scim.read"},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = uaa.Seed(testserver.Fixtures{
+					Groups: []testserver.GroupFixture{
+						{ID: "some-group-id", DisplayName: "scim.write"},
+					},
+				})
+				Expect(err).To(MatchError("group id already in use: some-group-id"))
+
+				groups, err := client.Groups.List(warrant.Query{}, token)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(groups).To(HaveLen(1))
+				Expect(groups[0].DisplayName).To(Equal("scim.read"))
+			})
+
+			It("returns an error when a client already exists", func() {
+				err := uaa.Seed(testserver.Fixtures{
+					Clients: []testserver.ClientFixture{
+						{ID: "admin"},
+					},
+				})
+				Expect(err).To(MatchError("client already exists: admin"))
+			})
+
+			It("returns an error when a client is invalid", func() {
+				err := uaa.Seed(testserver.Fixtures{
+					Clients: []testserver.ClientFixture{
+						{ID: "app", AuthorizedGrantTypes: []string{"magic"}},
+					},
+				})
+				Expect(err).To(MatchError(ContainSubstring(`client "app" is invalid: magic is not an allowed grant type`)))
+			})
+		})
+	})
+
+	Describe("SeedFile", func() {
+		It("seeds fixtures from a YAML file", func() {
+			path := writeFile("fixtures.yml", `
+users:
+- userName: alice
+  password: password
+  emails: [alice@example.com]
+groups:
+- displayName: scim.read
+  members: [alice]
+clients:
+- client_id: app
+  client_secret: secret
+  authorized_grant_types: [client_credentials]
+  authorities: [scim.read]
+`)
+			Expect(uaa.SeedFile(path)).To(Succeed())
+
+			users, err := client.Users.List(warrant.Query{}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+			Expect(users[0].UserName).To(Equal("alice"))
+
+			groups, err := client.Groups.List(warrant.Query{}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(groups).To(HaveLen(1))
+
+			_, err = client.Clients.GetToken("app", "secret")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("seeds fixtures from a JSON file", func() {
+			path := writeFile("fixtures.json", `{
+				"users": [{"userName": "alice", "password": "password"}],
+				"clients": [{"client_id": "app", "client_secret": "secret", "authorized_grant_types": ["client_credentials"]}]
+			}`)
+			Expect(uaa.SeedFile(path)).To(Succeed())
+
+			users, err := client.Users.List(warrant.Query{}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+
+			_, err = client.Clients.GetToken("app", "secret")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the file contains unknown fields", func() {
+				path := writeFile("fixtures.yaml", "users:\n- username: alice\n")
+
+				err := uaa.SeedFile(path)
+				Expect(err).To(MatchError(ContainSubstring("could not parse fixtures from " + path)))
+			})
+
+			It("returns an error when the file does not exist", func() {
+				err := uaa.SeedFile(filepath.Join(dir, "missing.yml"))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package testserver_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTestserverSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "testserver")
+}
@@ -0,0 +1,123 @@
+package testserver
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/server/domain"
+)
+
+type snapshot struct {
+	Users   domain.UsersList   `json:"users"`
+	Groups  domain.GroupsList  `json:"groups"`
+	Clients domain.ClientsList `json:"clients"`
+	Keys    snapshotKeys       `json:"keys"`
+}
+
+type snapshotKeys struct {
+	PublicKey   string        `json:"public_key"`
+	PrivateKey  string        `json:"private_key"`
+	ActiveKeyID string        `json:"active_key_id,omitempty"`
+	SigningKeys []snapshotKey `json:"signing_keys,omitempty"`
+}
+
+type snapshotKey struct {
+	ID         string `json:"id"`
+	Algorithm  string `json:"algorithm"`
+	PublicKey  string `json:"public_key"`
+	PrivateKey string `json:"private_key"`
+}
+
+// Snapshot returns a JSON document capturing the users, groups,
+// clients, and token signing keys held by the server. The document
+// can be given to Restore to return the server to this state, which
+// allows a directory of fixtures to be seeded once and rolled back
+// to between tests.
+func (s *UAA) Snapshot() ([]byte, error) {
+	active := s.tokens.ActiveKey()
+
+	var keys []snapshotKey
+	for _, key := range s.tokens.Keys() {
+		keys = append(keys, snapshotKey{
+			ID:         key.ID,
+			Algorithm:  key.Algorithm,
+			PublicKey:  key.PublicKey,
+			PrivateKey: key.PrivateKey,
+		})
+	}
+
+	return json.Marshal(snapshot{
+		Users:   s.users.All(),
+		Groups:  s.groups.All(),
+		Clients: s.clients.All(),
+		Keys: snapshotKeys{
+			PublicKey:   active.PublicKey,
+			PrivateKey:  active.PrivateKey,
+			ActiveKeyID: active.ID,
+			SigningKeys: keys,
+		},
+	})
+}
+
+// Restore replaces the users, groups, clients, and token signing
+// keys held by the server with those captured in the given snapshot.
+// As with Reset, all token revocations and outstanding authorization
+// codes will be forgotten. The signing keys are left unchanged if the
+// snapshot does not include them, and the configured admin client is
+// kept whether or not the snapshot includes it.
+func (s *UAA) Restore(data []byte) error {
+	var snap snapshot
+	err := json.Unmarshal(data, &snap)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(snap.Keys.SigningKeys) > 0:
+		var keys []domain.SigningKey
+		for _, key := range snap.Keys.SigningKeys {
+			keys = append(keys, domain.SigningKey{
+				ID:         key.ID,
+				Algorithm:  key.Algorithm,
+				PublicKey:  key.PublicKey,
+				PrivateKey: key.PrivateKey,
+			})
+		}
+
+		err = s.tokens.ReplaceKeys(keys, snap.Keys.ActiveKeyID)
+	case snap.Keys.PublicKey != "" || snap.Keys.PrivateKey != "":
+		err = s.tokens.SetKeys(snap.Keys.PublicKey, snap.Keys.PrivateKey)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.users.Replace(snap.Users)
+	s.groups.Replace(snap.Groups)
+	s.clients.Replace(snap.Clients)
+	s.tokens.ClearRevocations()
+	s.tokens.ClearAuthorizationCodes()
+
+	return nil
+}
+
+// SaveSnapshot writes the result of Snapshot to the file at the given path.
+func (s *UAA) SaveSnapshot(path string) error {
+	data, err := s.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// LoadSnapshot restores the server from a snapshot previously written
+// to the file at the given path by SaveSnapshot.
+func (s *UAA) LoadSnapshot(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return s.Restore(data)
+}
@@ -1,7 +1,10 @@
 package testserver
 
 import (
+	"crypto/tls"
+	"net/http"
 	"net/http/httptest"
+	"sync"
 
 	"github.com/gorilla/mux"
 	"github.com/pivotal-cf-experimental/warrant/internal/server/clients"
@@ -27,43 +30,91 @@ var defaultScopes = []string{
 
 // UAA is a fake implementation of the UAA HTTP service.
 type UAA struct {
-	server  *httptest.Server
-	users   *domain.Users
-	clients *domain.Clients
-	groups  *domain.Groups
-	tokens  *domain.Tokens
+	server        *httptest.Server
+	users         *domain.Users
+	clients       *domain.Clients
+	groups        *domain.Groups
+	tokens        *domain.Tokens
+	defaultScopes []string
+	tls           *tls.Config
 
-	publicKey  string
-	privateKey string
+	mutex sync.RWMutex
+	url   string
 }
 
-// NewUAA returns a new UAA initialized with the given Config.
+// NewUAA returns a new UAA initialized with the default Config.
 func NewUAA() *UAA {
-	privateKey := common.TestPrivateKey
-	publicKey := common.TestPublicKey
+	uaa, err := NewUAAWithConfig(Config{})
+	if err != nil {
+		panic(err)
+	}
+
+	return uaa
+}
 
-	tokensCollection := domain.NewTokens(publicKey, privateKey, defaultScopes)
+// NewUAAWithConfig returns a new UAA initialized with the given Config.
+// An error is returned if the signing key in the Config is invalid.
+func NewUAAWithConfig(config Config) (*UAA, error) {
+	scopes := config.DefaultScopes
+	if scopes == nil {
+		scopes = defaultScopes
+	}
+
+	tokensCollection := domain.NewTokens(common.TestPublicKey, common.TestPrivateKey, scopes)
 	usersCollection := domain.NewUsers()
 	clientsCollection := domain.NewClients()
 	groupsCollection := domain.NewGroups()
 
 	router := mux.NewRouter()
 	uaa := &UAA{
-		server:     httptest.NewUnstartedServer(router),
-		tokens:     tokensCollection,
-		users:      usersCollection,
-		clients:    clientsCollection,
-		groups:     groupsCollection,
-		privateKey: privateKey,
-		publicKey:  publicKey,
+		server:        httptest.NewUnstartedServer(router),
+		tokens:        tokensCollection,
+		users:         usersCollection,
+		clients:       clientsCollection,
+		groups:        groupsCollection,
+		defaultScopes: scopes,
+		tls:           config.TLS,
+	}
+
+	if config.SigningKey != "" || config.SigningAlgorithm != "" {
+		signingKey := config.SigningKey
+		if signingKey == "" {
+			signingKey = common.TestPrivateKey
+		}
+
+		if err := uaa.SetSigningKeyWithAlgorithm(config.SigningAlgorithm, signingKey); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.KeyID != "" {
+		tokensCollection.SetKeyID(config.KeyID)
+	}
+
+	tokensCollection.SetIssuer(config.Issuer)
+
+	accessTokenValidity := config.AccessTokenValidity
+	if accessTokenValidity == 0 {
+		accessTokenValidity = domain.DefaultAccessTokenValidity
+	}
+	tokensCollection.SetTokenValidity(accessTokenValidity, config.RefreshTokenValidity)
+
+	if config.AdminClientID != "" || config.AdminClientSecret != "" {
+		admin := clientsCollection.Admin()
+		if config.AdminClientID != "" {
+			admin.ID = config.AdminClientID
+			admin.Name = config.AdminClientID
+		}
+		if config.AdminClientSecret != "" {
+			admin.Secret = config.AdminClientSecret
+		}
+		clientsCollection.SetAdmin(admin)
 	}
 
 	tokenRouter := tokens.NewRouter(
 		tokensCollection,
 		usersCollection,
 		clientsCollection,
-		publicKey,
-		privateKey,
 		uaa)
 
 	router.Handle("/Users{a:.*}", users.NewRouter(usersCollection, tokensCollection))
@@ -71,21 +122,30 @@ func NewUAA() *UAA {
 	router.Handle("/oauth/clients{a:.*}", clients.NewRouter(clientsCollection, tokensCollection))
 	router.Handle("/oauth{a:.*}", tokenRouter)
 	router.Handle("/token_key{a:.*}", tokenRouter)
+	router.Handle("/check_token", tokenRouter)
 
-	return uaa
+	return uaa, nil
 }
 
 func (s *UAA) PublicKey() string {
-	return s.publicKey
+	return s.tokens.PublicKey()
 }
 
 func (s *UAA) PrivateKey() string {
-	return s.privateKey
+	return s.tokens.PrivateKey()
 }
 
 // Start will cause the HTTP server to bind to a port
-// and start serving requests.
+// and start serving requests. The server serves TLS
+// when the Config it was created with included TLS
+// settings.
 func (s *UAA) Start() {
+	if s.tls != nil {
+		s.server.TLS = s.tls
+		s.server.StartTLS()
+		return
+	}
+
 	s.server.Start()
 }
 
@@ -97,28 +157,130 @@ func (s *UAA) Close() {
 
 // Reset will clear all internal resource state within
 // the server. This means that all users, clients, and
-// groups will be deleted.
+// groups will be deleted, and all token revocations
+// and outstanding authorization codes will be forgotten.
 func (s *UAA) Reset() {
 	s.users.Clear()
 	s.clients.Clear()
 	s.groups.Clear()
+	s.tokens.ClearRevocations()
+	s.tokens.ClearAuthorizationCodes()
 }
 
 // URL returns the url that the server is hosted on.
 func (s *UAA) URL() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.url != "" {
+		return s.url
+	}
+
 	return s.server.URL
 }
 
+// SetURL overrides the url that the server reports it is hosted
+// on, which is used as the issuer of the tokens it grants. This is
+// needed when the server is not started using Start, but is instead
+// served by an http.Server of your own.
+func (s *UAA) SetURL(url string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.url = url
+}
+
+// ServeHTTP allows the UAA to be served by an http.Server of your
+// own rather than by calling Start.
+func (s *UAA) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.server.Config.Handler.ServeHTTP(w, req)
+}
+
+// SetSigningKey replaces the key used to sign tokens with the given
+// PEM encoded private key. RSA keys may be given in PKCS #1 or PKCS #8
+// form, ECDSA keys in SEC 1 or PKCS #8 form, and Ed25519 keys in
+// PKCS #8 form. Tokens are signed using RS256 for RSA keys, ES256,
+// ES384, or ES512 for ECDSA keys depending on their curve, and EdDSA
+// for Ed25519 keys. The corresponding public key is published by the
+// /token_key and /token_keys endpoints.
+func (s *UAA) SetSigningKey(privateKey string) error {
+	return s.SetSigningKeyWithAlgorithm("", privateKey)
+}
+
+// SetSigningKeyWithAlgorithm is like SetSigningKey, but signs tokens
+// using the given JSON Web Algorithm, such as "PS256". The default
+// algorithm for the key is used when the algorithm is empty.
+func (s *UAA) SetSigningKeyWithAlgorithm(algorithm, privateKey string) error {
+	key, err := domain.NewSigningKey("", algorithm, privateKey)
+	if err != nil {
+		return err
+	}
+
+	return s.tokens.SetActiveKey(key)
+}
+
+// AddSigningKey adds the given PEM encoded private key to the keys
+// held by the server under the given key id. The key may be any of
+// the kinds accepted by SetSigningKey. The public key is published by
+// the /token_keys endpoint straight away, but the key is not used to
+// sign tokens until it is activated using ActivateSigningKey.
+func (s *UAA) AddSigningKey(keyID, privateKey string) error {
+	return s.AddSigningKeyWithAlgorithm(keyID, "", privateKey)
+}
+
+// AddSigningKeyWithAlgorithm is like AddSigningKey, but the key is
+// used to sign tokens using the given JSON Web Algorithm once it is
+// activated.
+func (s *UAA) AddSigningKeyWithAlgorithm(keyID, algorithm, privateKey string) error {
+	key, err := domain.NewSigningKey(keyID, algorithm, privateKey)
+	if err != nil {
+		return err
+	}
+
+	return s.tokens.AddKey(key)
+}
+
+// RemoveSigningKey removes the key with the given id from the keys
+// held by the server. Tokens signed with the key are no longer
+// accepted by the server. The active key cannot be removed.
+func (s *UAA) RemoveSigningKey(keyID string) error {
+	return s.tokens.RemoveKey(keyID)
+}
+
+// ActivateSigningKey causes the key with the given id to be used to
+// sign all tokens granted from now on, and to be published by the
+// /token_key endpoint. Tokens signed with previously active keys are
+// still accepted until those keys are removed.
+func (s *UAA) ActivateSigningKey(keyID string) error {
+	return s.tokens.ActivateKey(keyID)
+}
+
+// SigningKeyIDs returns the ids of the keys held by the server, in
+// the order they were added.
+func (s *UAA) SigningKeyIDs() []string {
+	var ids []string
+	for _, key := range s.tokens.Keys() {
+		ids = append(ids, key.ID)
+	}
+
+	return ids
+}
+
+// ActiveSigningKeyID returns the id of the key used to sign tokens.
+func (s *UAA) ActiveSigningKeyID() string {
+	return s.tokens.KeyID()
+}
+
 // SetDefaultScopes allows the default scopes applied to a
 // user to be configured.
 func (s *UAA) SetDefaultScopes(scopes []string) {
-	s.tokens.DefaultScopes = scopes
-} // TODO: move this configuration onto the Config
+	s.tokens.SetDefaultScopes(scopes)
+}
 
-// ResetDefaultScopes resets the default scopes back to their
-// original values.
+// ResetDefaultScopes resets the default scopes back to the
+// values the server was configured with.
 func (s *UAA) ResetDefaultScopes() {
-	s.tokens.DefaultScopes = defaultScopes
+	s.tokens.SetDefaultScopes(s.defaultScopes)
 }
 
 // UserTokenFor returns a user token with the given id,
@@ -0,0 +1,712 @@
+package testserver_test
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pivotal-cf-experimental/warrant"
+	"github.com/pivotal-cf-experimental/warrant/testserver"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UAA", func() {
+	var (
+		uaa    *testserver.UAA
+		client warrant.Warrant
+		token  string
+	)
+
+	BeforeEach(func() {
+		uaa = testserver.NewUAA()
+		uaa.Start()
+
+		client = warrant.New(warrant.Config{
+			Host:          uaa.URL(),
+			SkipVerifySSL: true,
+		})
+
+		var err error
+		token, err = client.Clients.GetToken("admin", "admin")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		uaa.Close()
+	})
+
+	Describe("serving the UAA from another server", func() {
+		It("uses the configured url as the issuer of tokens", func() {
+			server := httptest.NewServer(uaa)
+			defer server.Close()
+
+			uaa.SetURL(server.URL)
+			Expect(uaa.URL()).To(Equal(server.URL))
+
+			w := warrant.New(warrant.Config{Host: server.URL})
+			clientToken, err := w.Clients.GetToken("admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+
+			decodedToken, err := w.Tokens.Decode(clientToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.Issuer).To(Equal(server.URL + "/oauth/token"))
+		})
+	})
+
+	Describe("NewUAAWithConfig", func() {
+		var (
+			configuredUAA *testserver.UAA
+			privateKey    *rsa.PrivateKey
+		)
+
+		BeforeEach(func() {
+			var err error
+			privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).NotTo(HaveOccurred())
+
+			configuredUAA, err = testserver.NewUAAWithConfig(testserver.Config{
+				SigningKey: string(pem.EncodeToMemory(&pem.Block{
+					Type:  "RSA PRIVATE KEY",
+					Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+				})),
+				KeyID:               "some-key-id",
+				Issuer:              "https://uaa.example.com/oauth/token",
+				AccessTokenValidity: 10 * time.Minute,
+				AdminClientID:       "root",
+				AdminClientSecret:   "s3cr3t",
+				TLS:                 &tls.Config{},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			configuredUAA.Start()
+		})
+
+		AfterEach(func() {
+			configuredUAA.Close()
+		})
+
+		It("serves tokens using the given configuration", func() {
+			Expect(configuredUAA.URL()).To(HavePrefix("https://"))
+
+			w := warrant.New(warrant.Config{
+				Host:          configuredUAA.URL(),
+				SkipVerifySSL: true,
+			})
+
+			_, err := w.Clients.GetToken("admin", "admin")
+			Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+
+			clientToken, err := w.Clients.GetToken("root", "s3cr3t")
+			Expect(err).NotTo(HaveOccurred())
+
+			decodedToken, err := w.Tokens.Decode(clientToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.KeyID).To(Equal("some-key-id"))
+			Expect(decodedToken.Issuer).To(Equal("https://uaa.example.com/oauth/token"))
+			Expect(decodedToken.ExpiresAt.Sub(decodedToken.IssuedAt)).To(Equal(10 * time.Minute))
+
+			keys, err := w.Tokens.GetSigningKeys()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(HaveLen(1))
+			Expect(keys[0].KeyId).To(Equal("some-key-id"))
+			Expect(decodedToken.Verify(keys)).To(Succeed())
+		})
+
+		It("keeps the configured admin client when reset", func() {
+			configuredUAA.Reset()
+
+			w := warrant.New(warrant.Config{
+				Host:          configuredUAA.URL(),
+				SkipVerifySSL: true,
+			})
+
+			_, err := w.Clients.GetToken("root", "s3cr3t")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("uses the configured issuer for user and refreshed tokens", func() {
+			w := warrant.New(warrant.Config{
+				Host:          configuredUAA.URL(),
+				SkipVerifySSL: true,
+			})
+
+			adminToken, err := w.Clients.GetToken("root", "s3cr3t")
+			Expect(err).NotTo(HaveOccurred())
+
+			client := warrant.Client{
+				ID:                   "some-client",
+				Scope:                []string{"openid"},
+				AuthorizedGrantTypes: []string{"password", "refresh_token"},
+			}
+			Expect(w.Clients.Create(client, "", adminToken)).To(Succeed())
+
+			_, err = w.Users.Create("username", "user@example.com", adminToken)
+			Expect(err).NotTo(HaveOccurred())
+
+			response, err := w.Users.GetTokenResponse("username", "password", client)
+			Expect(err).NotTo(HaveOccurred())
+
+			decodedToken, err := w.Tokens.Decode(response.AccessToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.Issuer).To(Equal("https://uaa.example.com/oauth/token"))
+
+			refreshed, err := w.Tokens.RefreshToken(response.RefreshToken, "some-client", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			decodedToken, err = w.Tokens.Decode(refreshed.AccessToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.Issuer).To(Equal("https://uaa.example.com/oauth/token"))
+		})
+
+		It("returns an error when the signing key cannot be parsed", func() {
+			_, err := testserver.NewUAAWithConfig(testserver.Config{SigningKey: "not a key"})
+			Expect(err).To(MatchError("failed to decode PEM block containing private key"))
+		})
+	})
+
+	Describe("SetSigningKey", func() {
+		It("signs tokens with the given key", func() {
+			privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = uaa.SetSigningKey(string(pem.EncodeToMemory(&pem.Block{
+				Type:  "RSA PRIVATE KEY",
+				Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+			})))
+			Expect(err).NotTo(HaveOccurred())
+
+			key, err := client.Tokens.GetSigningKey()
+			Expect(err).NotTo(HaveOccurred())
+
+			block, _ := pem.Decode([]byte(key.Value))
+			Expect(block).NotTo(BeNil())
+			Expect(block.Type).To(Equal("PUBLIC KEY"))
+			publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(publicKey.(*rsa.PublicKey).N).To(Equal(privateKey.PublicKey.N))
+
+			clientToken, err := client.Clients.GetToken("admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+
+			decodedToken, err := client.Tokens.Decode(clientToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.Verify([]warrant.SigningKey{key})).To(Succeed())
+		})
+
+		It("returns an error when the key cannot be parsed", func() {
+			err := uaa.SetSigningKey("not a key")
+			Expect(err).To(MatchError("failed to decode PEM block containing private key"))
+		})
+
+		It("returns an error when the key cannot be used for signing", func() {
+			privateKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+
+			der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = uaa.SetSigningKey(string(pem.EncodeToMemory(&pem.Block{
+				Type:  "PRIVATE KEY",
+				Bytes: der,
+			})))
+			Expect(err).To(MatchError("signing key type is not supported"))
+		})
+	})
+
+	Describe("signing key rotation", func() {
+		var rotatedKey string
+
+		BeforeEach(func() {
+			rotatedKey = generatePrivateKey()
+			Expect(uaa.AddSigningKey("rotated-key", rotatedKey)).To(Succeed())
+		})
+
+		It("publishes added keys without signing with them", func() {
+			Expect(uaa.SigningKeyIDs()).To(Equal([]string{"legacy-token-key", "rotated-key"}))
+			Expect(uaa.ActiveSigningKeyID()).To(Equal("legacy-token-key"))
+
+			keys, err := client.Tokens.GetSigningKeys()
+			Expect(err).NotTo(HaveOccurred())
+
+			var ids []string
+			for _, key := range keys {
+				ids = append(ids, key.KeyId)
+			}
+			Expect(ids).To(ConsistOf("legacy-token-key", "token-key", "rotated-key"))
+
+			decodedToken, err := client.Tokens.Decode(token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.KeyID).To(Equal("legacy-token-key"))
+		})
+
+		It("signs tokens with the activated key", func() {
+			Expect(uaa.ActivateSigningKey("rotated-key")).To(Succeed())
+
+			key, err := client.Tokens.GetSigningKey()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(key.KeyId).To(Equal("rotated-key"))
+
+			rotatedToken, err := client.Clients.GetToken("admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+
+			decodedToken, err := client.Tokens.Decode(rotatedToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.KeyID).To(Equal("rotated-key"))
+
+			keys, err := client.Tokens.GetSigningKeys()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.Verify(keys)).To(Succeed())
+
+			_, err = client.Users.List(warrant.Query{}, rotatedToken)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.Users.List(warrant.Query{}, token)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects tokens signed with removed keys", func() {
+			Expect(uaa.ActivateSigningKey("rotated-key")).To(Succeed())
+			Expect(uaa.RemoveSigningKey("legacy-token-key")).To(Succeed())
+			Expect(uaa.SigningKeyIDs()).To(Equal([]string{"rotated-key"}))
+
+			_, err := client.Users.List(warrant.Query{}, token)
+			Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+		})
+
+		It("restores the keys captured in a snapshot", func() {
+			Expect(uaa.ActivateSigningKey("rotated-key")).To(Succeed())
+
+			snapshot, err := uaa.Snapshot()
+			Expect(err).NotTo(HaveOccurred())
+
+			other := testserver.NewUAA()
+			Expect(other.Restore(snapshot)).To(Succeed())
+			Expect(other.SigningKeyIDs()).To(Equal([]string{"legacy-token-key", "rotated-key"}))
+			Expect(other.ActiveSigningKeyID()).To(Equal("rotated-key"))
+			Expect(other.PrivateKey()).To(Equal(rotatedKey))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when adding a key with an id that is in use", func() {
+				err := uaa.AddSigningKey("rotated-key", generatePrivateKey())
+				Expect(err).To(MatchError("signing key already exists: rotated-key"))
+			})
+
+			It("returns an error when adding a key that cannot be parsed", func() {
+				err := uaa.AddSigningKey("other-key", "not a key")
+				Expect(err).To(MatchError("failed to decode PEM block containing private key"))
+			})
+
+			It("returns an error when removing the active key", func() {
+				err := uaa.RemoveSigningKey("legacy-token-key")
+				Expect(err).To(MatchError("cannot remove the active signing key: legacy-token-key"))
+			})
+
+			It("returns an error when the key does not exist", func() {
+				Expect(uaa.ActivateSigningKey("missing-key")).To(MatchError("signing key does not exist: missing-key"))
+				Expect(uaa.RemoveSigningKey("missing-key")).To(MatchError("signing key does not exist: missing-key"))
+			})
+		})
+	})
+
+	Describe("signing algorithms", func() {
+		for _, example := range []struct {
+			algorithm  string
+			privateKey func() string
+		}{
+			{"ES256", func() string { return generateECPrivateKey(elliptic.P256()) }},
+			{"ES384", func() string { return generateECPrivateKey(elliptic.P384()) }},
+			{"ES512", func() string { return generateECPrivateKey(elliptic.P521()) }},
+			{"EdDSA", generateEd25519PrivateKey},
+			{"PS256", generatePrivateKey},
+			{"RS512", generatePrivateKey},
+		} {
+			algorithm, privateKey := example.algorithm, example.privateKey
+
+			It(fmt.Sprintf("signs tokens using %s", algorithm), func() {
+				Expect(uaa.AddSigningKeyWithAlgorithm("other-key", algorithm, privateKey())).To(Succeed())
+				Expect(uaa.ActivateSigningKey("other-key")).To(Succeed())
+
+				otherToken, err := client.Clients.GetToken("admin", "admin")
+				Expect(err).NotTo(HaveOccurred())
+
+				decodedToken, err := client.Tokens.Decode(otherToken)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decodedToken.Algorithm).To(Equal(algorithm))
+				Expect(decodedToken.KeyID).To(Equal("other-key"))
+
+				keys, err := client.Tokens.GetSigningKeys()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decodedToken.Verify(keys)).To(Succeed())
+
+				_, err = client.Users.List(warrant.Query{}, otherToken)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		}
+
+		It("infers the algorithm from the key when none is given", func() {
+			Expect(uaa.SetSigningKey(generateECPrivateKey(elliptic.P384()))).To(Succeed())
+
+			otherToken, err := client.Clients.GetToken("admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+
+			decodedToken, err := client.Tokens.Decode(otherToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.Algorithm).To(Equal("ES384"))
+		})
+
+		It("configures the algorithm of the signing key", func() {
+			other, err := testserver.NewUAAWithConfig(testserver.Config{
+				SigningKey:       generateEd25519PrivateKey(),
+				SigningAlgorithm: "EdDSA",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			other.Start()
+			defer other.Close()
+
+			otherClient := warrant.New(warrant.Config{Host: other.URL()})
+			otherToken, err := otherClient.Clients.GetToken("admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+
+			decodedToken, err := otherClient.Tokens.Decode(otherToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.Algorithm).To(Equal("EdDSA"))
+
+			keys, err := otherClient.Tokens.GetSigningKeys()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.Verify(keys)).To(Succeed())
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the algorithm does not suit the key", func() {
+				err := uaa.AddSigningKeyWithAlgorithm("other-key", "ES256", generatePrivateKey())
+				Expect(err).To(MatchError(`signing key cannot be used with the "ES256" algorithm`))
+
+				err = uaa.SetSigningKeyWithAlgorithm("ES512", generateECPrivateKey(elliptic.P256()))
+				Expect(err).To(MatchError(`signing key cannot be used with the "ES512" algorithm`))
+			})
+
+			It("returns an error when the algorithm is not supported", func() {
+				err := uaa.AddSigningKeyWithAlgorithm("other-key", "HS256", generatePrivateKey())
+				Expect(err).To(MatchError(`signing key cannot be used with the "HS256" algorithm`))
+			})
+		})
+	})
+
+	Describe("Snapshot and Restore", func() {
+		var (
+			user   warrant.User
+			group  warrant.Group
+			member warrant.Client
+		)
+
+		BeforeEach(func() {
+			var err error
+			user, err = client.Users.Create("some-user", "some-user@example.com", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = client.Users.SetPassword(user.ID, "password", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			group, err = client.Groups.Create("some-group", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.Groups.AddMember(group.ID, user.ID, token)
+			Expect(err).NotTo(HaveOccurred())
+
+			member = warrant.Client{
+				ID:                   "some-client",
+				Scope:                []string{"openid"},
+				ResourceIDs:          []string{"none"},
+				Authorities:          []string{"scim.read"},
+				AuthorizedGrantTypes: []string{"client_credentials"},
+			}
+			err = client.Clients.Create(member, "secret", token)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rolls the server back to the state captured in the snapshot", func() {
+			snapshot, err := uaa.Snapshot()
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.Users.Create("other-user", "other-user@example.com", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = client.Groups.Delete(group.ID, token)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = client.Clients.Delete(member.ID, token)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(uaa.Restore(snapshot)).To(Succeed())
+
+			users, err := client.Users.List(warrant.Query{}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+			Expect(users[0].ID).To(Equal(user.ID))
+
+			_, err = client.Users.GetToken("some-user", "password", warrant.Client{ID: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, isMember, err := client.Groups.CheckMembership(group.ID, user.ID, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isMember).To(BeTrue())
+
+			_, err = client.Clients.GetToken(member.ID, "secret")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("restores the token signing keys", func() {
+			snapshot, err := uaa.Snapshot()
+			Expect(err).NotTo(HaveOccurred())
+
+			other := testserver.NewUAA()
+			Expect(other.Restore(snapshot)).To(Succeed())
+			Expect(other.PublicKey()).To(Equal(uaa.PublicKey()))
+			Expect(other.PrivateKey()).To(Equal(uaa.PrivateKey()))
+		})
+
+		It("keeps the admin client when the snapshot does not include it", func() {
+			Expect(uaa.Restore([]byte(`{"clients": []}`))).To(Succeed())
+
+			_, err := client.Clients.GetToken("admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.Clients.Get(member.ID, token)
+			Expect(err).To(BeAssignableToTypeOf(warrant.NotFoundError{}))
+		})
+
+		It("saves and loads snapshots using a file", func() {
+			dir, err := ioutil.TempDir("", "snapshot")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "snapshot.json")
+			Expect(uaa.SaveSnapshot(path)).To(Succeed())
+
+			uaa.Reset()
+
+			_, err = client.Users.Get(user.ID, token)
+			Expect(err).To(BeAssignableToTypeOf(warrant.NotFoundError{}))
+
+			Expect(uaa.LoadSnapshot(path)).To(Succeed())
+
+			fetchedUser, err := client.Users.Get(user.ID, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetchedUser.UserName).To(Equal("some-user"))
+			Expect(fetchedUser.CreatedAt).To(BeTemporally("==", user.CreatedAt))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the snapshot is not JSON", func() {
+				Expect(uaa.Restore([]byte("not json"))).To(MatchError(ContainSubstring("invalid character")))
+			})
+
+			It("returns an error when the signing keys are invalid", func() {
+				err := uaa.Restore([]byte(`{"keys":{"public_key":"not a key","private_key":"not a key"}}`))
+				Expect(err).To(MatchError("failed to decode PEM block containing public key"))
+
+				_, err = client.Users.Get(user.ID, token)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns an error when the snapshot file does not exist", func() {
+				err := uaa.LoadSnapshot("/does/not/exist.json")
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+		})
+	})
+
+	// These specs are most useful when run with the race detector enabled.
+	Describe("concurrent requests", func() {
+		const (
+			workers    = 20
+			iterations = 4
+		)
+
+		It("serves requests from many clients at once", func() {
+			errs := make(chan error, workers*iterations)
+
+			var wg sync.WaitGroup
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < iterations; j++ {
+						errs <- exercise(client, token, fmt.Sprintf("%d-%d", i, j))
+					}
+				}(i)
+			}
+
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			users, err := client.Users.List(warrant.Query{}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(workers * iterations))
+
+			groups, err := client.Groups.List(warrant.Query{}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(groups).To(HaveLen(workers * iterations))
+
+			clients, err := client.Clients.List(warrant.Query{}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clients).To(HaveLen(workers*iterations + 1))
+		})
+
+		It("can be reset while requests are in flight", func() {
+			done := make(chan struct{})
+
+			var wg sync.WaitGroup
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < iterations; j++ {
+						// Resources may be removed by a concurrent Reset, so
+						// the errors returned here are expected.
+						exercise(client, token, fmt.Sprintf("%d-%d", i, j))
+					}
+				}(i)
+			}
+
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+
+			for {
+				select {
+				case <-done:
+					uaa.Reset()
+
+					users, err := client.Users.List(warrant.Query{}, token)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(users).To(BeEmpty())
+					return
+				default:
+					uaa.Reset()
+					uaa.SetDefaultScopes([]string{"openid"})
+					uaa.ResetDefaultScopes()
+				}
+			}
+
+		})
+	})
+})
+
+func exercise(client warrant.Warrant, token, suffix string) error {
+	user, err := client.Users.Create("user-"+suffix, "user-"+suffix+"@example.com", token)
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.Users.Get(user.ID, token); err != nil {
+		return err
+	}
+
+	if err := client.Users.SetPassword(user.ID, "password", token); err != nil {
+		return err
+	}
+
+	if _, err := client.Users.GetToken(user.UserName, "password", warrant.Client{ID: "admin"}); err != nil {
+		return err
+	}
+
+	if _, err := client.Users.List(warrant.Query{Where: warrant.Eq("userName", user.UserName)}, token); err != nil {
+		return err
+	}
+
+	group, err := client.Groups.Create("group-"+suffix, token)
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.Groups.AddMember(group.ID, user.ID, token); err != nil {
+		return err
+	}
+
+	if _, _, err := client.Groups.CheckMembership(group.ID, user.ID, token); err != nil {
+		return err
+	}
+
+	if _, err := client.Groups.List(warrant.Query{}, token); err != nil {
+		return err
+	}
+
+	c := warrant.Client{
+		ID:                   "client-" + suffix,
+		Scope:                []string{"openid"},
+		ResourceIDs:          []string{"none"},
+		Authorities:          []string{"scim.read"},
+		AuthorizedGrantTypes: []string{"client_credentials"},
+	}
+	if err := client.Clients.Create(c, "secret", token); err != nil {
+		return err
+	}
+
+	if _, err := client.Clients.GetToken(c.ID, "secret"); err != nil {
+		return err
+	}
+
+	if _, err := client.Clients.List(warrant.Query{}, token); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func generatePrivateKey() string {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	}))
+}
+
+func generateECPrivateKey(curve elliptic.Curve) string {
+	privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	der, err := x509.MarshalECPrivateKey(privateKey)
+	Expect(err).NotTo(HaveOccurred())
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "EC PRIVATE KEY",
+		Bytes: der,
+	}))
+}
+
+func generateEd25519PrivateKey() string {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	Expect(err).NotTo(HaveOccurred())
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: der,
+	}))
+}
@@ -1,11 +1,16 @@
 package warrant
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt"
 )
@@ -30,54 +35,221 @@ type Token struct {
 	// These values indicate the level of access granted by the user to this token.
 	Scopes []string `json:"scope"`
 
+	// Authorities are the values given in the "authorities" field of the token
+	// claims. These values indicate the level of access granted to the client.
+	Authorities []string `json:"authorities"`
+
+	// Audiences are the values given in the "aud" field of the token claims.
+	// These values identify the resource servers for which the token is intended.
+	Audiences []string `json:"-"`
+
 	// Issuer is the UAA endpoint that generated the token.
 	Issuer string `json:"iss"`
 
+	// JTI is the value given in the "jti" field of the token claims. This is the
+	// unique identifier of the token.
+	JTI string `json:"jti"`
+
+	// ExpiresAt is the time given in the "exp" field of the token claims. This
+	// value is the zero time when the claim is not present.
+	ExpiresAt time.Time `json:"-"`
+
+	// IssuedAt is the time given in the "iat" field of the token claims. This
+	// value is the zero time when the claim is not present.
+	IssuedAt time.Time `json:"-"`
+
+	// NotBefore is the time given in the "nbf" field of the token claims. This
+	// value is the zero time when the claim is not present.
+	NotBefore time.Time `json:"-"`
+
 	// Segments contains the raw token segment strings.
 	Segments TokenSegments
 }
 
 // Verify will use the given signing keys to verify the authenticity of the
-// token. Supports RSA and HMAC siging methods.
+// token. Supports the RSA (RS256, RS384, RS512), RSA-PSS (PS256, PS384,
+// PS512), ECDSA (ES256, ES384, ES512), EdDSA, and HMAC signing methods.
+//
+// The signing method is determined by the signing key, rather than by the
+// "alg" header of the token, which must match it. The Algorithm of the key,
+// given as either a JSON Web Algorithm name like "RS256" or a UAA name like
+// "SHA256withRSA", is used when present; otherwise the method must suit the
+// type of the public key. HMAC signatures are only accepted from keys whose
+// Algorithm names an HMAC method, and never from a PEM encoded public key.
+//
+// Verify does not check the claims of the token; use Validate to check its
+// expiry, audiences, scopes, and issuer.
 func (t Token) Verify(signingKeys []SigningKey) error {
 	for _, signingKey := range signingKeys {
 		if signingKey.KeyId == t.KeyID {
-			signingString := strings.Join([]string{t.Segments.Header, t.Segments.Claims}, ".")
-
-			switch t.Algorithm {
-			case jwt.SigningMethodRS256.Alg(), jwt.SigningMethodRS384.Alg(), jwt.SigningMethodRS512.Alg():
-				method := jwt.GetSigningMethod(t.Algorithm)
-
-				var err error
-				key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(signingKey.Value))
-				if err != nil {
-					block, rest := pem.Decode([]byte(signingKey.Value))
-					if len(rest) > 0 {
-						return errors.New("public key is not valid PEM encoding")
-					}
-
-					key, err = x509.ParsePKCS1PublicKey(block.Bytes)
-					if err != nil {
-						return err
-					}
-				}
-
-				return method.Verify(signingString, t.Segments.Signature, key)
-
-			case jwt.SigningMethodHS256.Alg(), jwt.SigningMethodHS384.Alg(), jwt.SigningMethodHS512.Alg():
-				method := jwt.GetSigningMethod(t.Algorithm)
-				key := []byte(signingKey.Value)
-				return method.Verify(signingString, t.Segments.Signature, key)
-
-			default:
-				return fmt.Errorf("unsupported token signing method: %s", t.Algorithm)
+			method, key, err := verificationMethod(t.Algorithm, signingKey)
+			if err != nil {
+				return err
 			}
+
+			signingString := strings.Join([]string{t.Segments.Header, t.Segments.Claims}, ".")
+			return method.Verify(signingString, t.Segments.Signature, key)
 		}
 	}
 
 	return errors.New("token was not signed by a known key")
 }
 
+// uaaAlgorithms maps the algorithm names published by UAA to their JSON Web
+// Algorithm equivalents.
+var uaaAlgorithms = map[string]string{
+	"SHA256withRSA":   "RS256",
+	"SHA384withRSA":   "RS384",
+	"SHA512withRSA":   "RS512",
+	"SHA256withECDSA": "ES256",
+	"SHA384withECDSA": "ES384",
+	"SHA512withECDSA": "ES512",
+	"HMACSHA256":      "HS256",
+	"HMACSHA384":      "HS384",
+	"HMACSHA512":      "HS512",
+}
+
+// verificationMethod returns the signing method and key with which to verify
+// a token signed using the given algorithm, as allowed by the signing key.
+func verificationMethod(algorithm string, signingKey SigningKey) (jwt.SigningMethod, interface{}, error) {
+	method := jwt.GetSigningMethod(algorithm)
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA, *jwt.SigningMethodEd25519, *jwt.SigningMethodHMAC:
+	default:
+		return nil, nil, fmt.Errorf("unsupported token signing method: %s", algorithm)
+	}
+
+	keyAlgorithm := signingKey.Algorithm
+	if name, ok := uaaAlgorithms[keyAlgorithm]; ok {
+		keyAlgorithm = name
+	}
+
+	if keyAlgorithm != "" && keyAlgorithm != algorithm {
+		return nil, nil, fmt.Errorf("token signing method %s does not match the %s algorithm of key %q", algorithm, signingKey.Algorithm, signingKey.KeyId)
+	}
+
+	if _, ok := method.(*jwt.SigningMethodHMAC); ok {
+		if keyAlgorithm == "" {
+			return nil, nil, fmt.Errorf("token signing method %s requires a key with an HMAC algorithm", algorithm)
+		}
+
+		if signingKey.Value == "" {
+			return nil, nil, fmt.Errorf("key %q does not have a value", signingKey.KeyId)
+		}
+
+		if block, _ := pem.Decode([]byte(signingKey.Value)); block != nil {
+			return nil, nil, fmt.Errorf("token signing method %s cannot be used with the public key %q", algorithm, signingKey.KeyId)
+		}
+
+		return method, []byte(signingKey.Value), nil
+	}
+
+	key, err := parsePublicKey(signingKey.Value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !keySupportsMethod(key, method) {
+		return nil, nil, fmt.Errorf("token signing method %s cannot be used with the public key %q", algorithm, signingKey.KeyId)
+	}
+
+	return method, key, nil
+}
+
+func parsePublicKey(value string) (interface{}, error) {
+	block, rest := pem.Decode([]byte(value))
+	if block == nil || len(bytes.TrimSpace(rest)) > 0 {
+		return nil, errors.New("public key is not valid PEM encoding")
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return x509.ParsePKCS1PublicKey(block.Bytes)
+}
+
+func keySupportsMethod(key interface{}, method jwt.SigningMethod) bool {
+	switch m := method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := key.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		k, ok := key.(*ecdsa.PublicKey)
+		return ok && k.Curve.Params().BitSize == m.CurveBits
+	case *jwt.SigningMethodEd25519:
+		_, ok := key.(ed25519.PublicKey)
+		return ok
+	default:
+		return false
+	}
+}
+
+// TokenValidation describes the checks made by Token.Validate against the
+// claims of a token.
+type TokenValidation struct {
+	// Time is the time against which the "exp", "nbf", and "iat" claims are
+	// checked. The current time is used when this value is the zero time.
+	Time time.Time
+
+	// ClockSkew is the amount of leeway allowed when checking the "exp", "nbf",
+	// and "iat" claims to account for differences between clocks.
+	ClockSkew time.Duration
+
+	// Audiences are the values that must all be present in the "aud" claim.
+	Audiences []string
+
+	// Scopes are the values that must all be present in the "scope" claim.
+	Scopes []string
+
+	// Issuer is the expected value of the "iss" claim. The issuer is not
+	// checked when this value is empty.
+	Issuer string
+}
+
+// Validate checks the claims of the token against the given TokenValidation.
+// The returned error is one of TokenExpiredError, TokenNotYetValidError,
+// TokenIssuedInFutureError, TokenIssuerError, TokenAudienceError, or
+// TokenScopeError. Claims that are not present in the token are not checked
+// for expiry, but are required to satisfy the audiences, scopes, and issuer.
+// Validate does not verify the signature of the token; use Verify for that.
+func (t Token) Validate(validation TokenValidation) error {
+	now := validation.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	if !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt.Add(validation.ClockSkew)) {
+		return TokenExpiredError{ExpiresAt: t.ExpiresAt}
+	}
+
+	if !t.NotBefore.IsZero() && now.Add(validation.ClockSkew).Before(t.NotBefore) {
+		return TokenNotYetValidError{NotBefore: t.NotBefore}
+	}
+
+	if !t.IssuedAt.IsZero() && now.Add(validation.ClockSkew).Before(t.IssuedAt) {
+		return TokenIssuedInFutureError{IssuedAt: t.IssuedAt}
+	}
+
+	if validation.Issuer != "" && t.Issuer != validation.Issuer {
+		return TokenIssuerError{Issuer: t.Issuer, Expected: validation.Issuer}
+	}
+
+	for _, audience := range validation.Audiences {
+		if !contains(t.Audiences, audience) {
+			return TokenAudienceError{Audience: audience}
+		}
+	}
+
+	for _, scope := range validation.Scopes {
+		if !contains(t.Scopes, scope) {
+			return TokenScopeError{Scope: scope}
+		}
+	}
+
+	return nil
+}
+
 // TokenSegments is the encoded token segments split into their named parts.
 type TokenSegments struct {
 	// Header is the raw token header segment.
@@ -89,3 +261,13 @@ type TokenSegments struct {
 	// Signature is the raw token signature segment.
 	Signature string
 }
+
+func contains(collection []string, item string) bool {
+	for _, elem := range collection {
+		if elem == item {
+			return true
+		}
+	}
+
+	return false
+}
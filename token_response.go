@@ -0,0 +1,64 @@
+package warrant
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/documents"
+)
+
+// TokenResponse is the representation of the tokens granted by UAA in
+// response to a request to the token endpoint.
+type TokenResponse struct {
+	// AccessToken is the encoded token value used to authenticate requests
+	// to UAA-based services.
+	AccessToken string
+
+	// RefreshToken is the encoded token value that can be exchanged for a new
+	// access token using the "refresh_token" grant type. This value is empty
+	// when UAA has not granted a refresh token.
+	RefreshToken string
+
+	// TokenType describes how the access token should be presented. This value
+	// is typically "bearer".
+	TokenType string
+
+	// Expiry is the time at which the access token expires, calculated from
+	// the lifetime reported by UAA when the token was received. This value is
+	// the zero time when UAA does not report a lifetime.
+	Expiry time.Time
+
+	// Scopes are the permission values granted to the access token.
+	Scopes []string
+
+	// JTI is the unique identifier of the access token.
+	JTI string
+}
+
+func newTokenResponseFromDocument(document documents.TokenResponse) TokenResponse {
+	var expiry time.Time
+	if document.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(document.ExpiresIn) * time.Second)
+	}
+
+	return TokenResponse{
+		AccessToken:  document.AccessToken,
+		RefreshToken: document.RefreshToken,
+		TokenType:    document.TokenType,
+		Expiry:       expiry,
+		Scopes:       strings.Fields(document.Scope),
+		JTI:          document.JTI,
+	}
+}
+
+// CheckTokenResponse is the representation of the result of checking a token
+// with UAA.
+type CheckTokenResponse struct {
+	// Active indicates whether UAA considers the token to be valid. A token is
+	// inactive once it has expired or been revoked.
+	Active bool
+
+	// Token contains the claims of the token as reported by UAA. This value is
+	// empty when the token is inactive.
+	Token Token
+}
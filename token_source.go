@@ -0,0 +1,162 @@
+package warrant
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/pivotal-cf-experimental/warrant/internal/network"
+)
+
+// DefaultTokenExpiryDelta is the amount of time before its expiry that a
+// RefreshingTokenSource will consider a cached token stale and fetch a new one.
+const DefaultTokenExpiryDelta = 30 * time.Second
+
+// TokenSource supplies the tokens used to authenticate requests to UAA. When
+// a TokenSource is set in the Config, services will use it to retrieve a
+// token for any method that is called with an empty token argument.
+type TokenSource interface {
+	// Token returns a token that is valid for use in a request to UAA.
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticTokenSource returns a TokenSource that always returns the given token.
+func StaticTokenSource(token string) TokenSource {
+	return staticTokenSource(token)
+}
+
+type staticTokenSource string
+
+func (s staticTokenSource) Token(ctx context.Context) (string, error) {
+	return string(s), nil
+}
+
+// RefreshingTokenSource is a TokenSource that caches the token retrieved from
+// UAA and fetches a new token shortly before the cached token expires. When
+// UAA has granted a refresh token, it is used to retrieve the new token. A
+// RefreshingTokenSource is safe for concurrent use by multiple goroutines.
+type RefreshingTokenSource struct {
+	// ExpiryDelta is the amount of time before its expiry that a cached token
+	// is considered stale. This value defaults to DefaultTokenExpiryDelta and
+	// should not be modified once the token source is in use.
+	ExpiryDelta time.Duration
+
+	mutex   sync.Mutex
+	current TokenResponse
+	fetch   func(ctx context.Context) (TokenResponse, error)
+	refresh func(ctx context.Context, refreshToken string) (TokenResponse, error)
+}
+
+// NewClientCredentialsTokenSource returns a RefreshingTokenSource that retrieves
+// tokens for the client with the given id and secret using the "client_credentials"
+// grant type.
+func NewClientCredentialsTokenSource(config Config, id, secret string) *RefreshingTokenSource {
+	clients := NewClientsService(config)
+
+	return &RefreshingTokenSource{
+		ExpiryDelta: DefaultTokenExpiryDelta,
+		fetch: func(ctx context.Context) (TokenResponse, error) {
+			return clients.GetTokenResponseWithContext(ctx, id, secret)
+		},
+	}
+}
+
+// NewPasswordTokenSource returns a RefreshingTokenSource that retrieves tokens
+// for the user with the given username and password on behalf of the given
+// client using the "password" grant type. If the client is authorized for the
+// "refresh_token" grant type, the refresh token is used to retrieve subsequent
+// tokens, falling back to the "password" grant should the refresh fail.
+func NewPasswordTokenSource(config Config, username, password string, client Client) *RefreshingTokenSource {
+	users := NewUsersService(config)
+	tokens := NewTokensService(config)
+
+	return &RefreshingTokenSource{
+		ExpiryDelta: DefaultTokenExpiryDelta,
+		fetch: func(ctx context.Context) (TokenResponse, error) {
+			return users.GetTokenResponseWithContext(ctx, username, password, client)
+		},
+		refresh: func(ctx context.Context, refreshToken string) (TokenResponse, error) {
+			return tokens.RefreshTokenWithContext(ctx, refreshToken, client.ID, "")
+		},
+	}
+}
+
+// NewRefreshTokenSource returns a RefreshingTokenSource that retrieves tokens by
+// exchanging the given refresh token on behalf of the client with the given id
+// and secret using the "refresh_token" grant type.
+func NewRefreshTokenSource(config Config, refreshToken, clientID, clientSecret string) *RefreshingTokenSource {
+	tokens := NewTokensService(config)
+
+	return &RefreshingTokenSource{
+		ExpiryDelta: DefaultTokenExpiryDelta,
+		current:     TokenResponse{RefreshToken: refreshToken},
+		refresh: func(ctx context.Context, refreshToken string) (TokenResponse, error) {
+			return tokens.RefreshTokenWithContext(ctx, refreshToken, clientID, clientSecret)
+		},
+	}
+}
+
+// Token returns the cached access token, retrieving a new token from UAA if
+// there is no cached token or it is about to expire.
+func (s *RefreshingTokenSource) Token(ctx context.Context) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.valid() {
+		return s.current.AccessToken, nil
+	}
+
+	response, err := s.retrieve(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if response.RefreshToken == "" {
+		response.RefreshToken = s.current.RefreshToken
+	}
+	s.current = response
+
+	return s.current.AccessToken, nil
+}
+
+func (s *RefreshingTokenSource) valid() bool {
+	if s.current.AccessToken == "" {
+		return false
+	}
+
+	if s.current.Expiry.IsZero() {
+		return true
+	}
+
+	return time.Now().Add(s.ExpiryDelta).Before(s.current.Expiry)
+}
+
+func (s *RefreshingTokenSource) retrieve(ctx context.Context) (TokenResponse, error) {
+	if s.current.RefreshToken != "" && s.refresh != nil {
+		response, err := s.refresh(ctx, s.current.RefreshToken)
+		if err == nil || s.fetch == nil {
+			return response, err
+		}
+	}
+
+	if s.fetch == nil {
+		return TokenResponse{}, errors.New("token source has no refresh token or credentials with which to retrieve a token")
+	}
+
+	return s.fetch(ctx)
+}
+
+// tokenAuthorization returns the bearer token authorization for a request to
+// UAA. The configured TokenSource is consulted only when the given token is empty.
+func tokenAuthorization(ctx context.Context, config Config, token string) (network.TokenAuthorization, error) {
+	if token == "" && config.TokenSource != nil {
+		var err error
+		token, err = config.TokenSource.Token(ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return network.NewTokenAuthorization(token), nil
+}
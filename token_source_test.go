@@ -0,0 +1,208 @@
+package warrant_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pivotal-cf-experimental/warrant"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TokenSource", func() {
+	var (
+		config     warrant.Config
+		adminToken string
+	)
+
+	BeforeEach(func() {
+		config = warrant.Config{
+			Host:          fakeUAA.URL(),
+			SkipVerifySSL: true,
+			TraceWriter:   TraceWriter,
+		}
+
+		var err error
+		adminToken, err = warrant.NewClientsService(config).GetToken("admin", "admin")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("StaticTokenSource", func() {
+		It("always returns the given token", func() {
+			source := warrant.StaticTokenSource("some-token")
+
+			token, err := source.Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("some-token"))
+		})
+	})
+
+	Describe("NewClientCredentialsTokenSource", func() {
+		It("caches the token until it is about to expire", func() {
+			source := warrant.NewClientCredentialsTokenSource(config, "admin", "admin")
+
+			token, err := source.Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).NotTo(BeEmpty())
+
+			decodedToken, err := warrant.NewTokensService(config).Decode(token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.ClientID).To(Equal("admin"))
+
+			cachedToken, err := source.Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cachedToken).To(Equal(token))
+		})
+
+		It("fetches a new token when the cached token is about to expire", func() {
+			source := warrant.NewClientCredentialsTokenSource(config, "admin", "admin")
+			source.ExpiryDelta = 24 * time.Hour
+
+			token, err := source.Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			newToken, err := source.Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newToken).NotTo(Equal(token))
+		})
+
+		It("is safe for concurrent use", func() {
+			source := warrant.NewClientCredentialsTokenSource(config, "admin", "admin")
+
+			var wg sync.WaitGroup
+			tokens := make([]string, 10)
+			errs := make([]error, 10)
+			for i := range tokens {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					tokens[i], errs[i] = source.Token(context.Background())
+				}(i)
+			}
+			wg.Wait()
+
+			for i := range tokens {
+				Expect(errs[i]).NotTo(HaveOccurred())
+				Expect(tokens[i]).To(Equal(tokens[0]))
+			}
+		})
+
+		It("returns an error when the client credentials are invalid", func() {
+			source := warrant.NewClientCredentialsTokenSource(config, "unknown-client", "secret")
+
+			_, err := source.Token(context.Background())
+			Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+		})
+	})
+
+	Describe("NewPasswordTokenSource", func() {
+		var (
+			client warrant.Client
+			user   warrant.User
+		)
+
+		BeforeEach(func() {
+			client = warrant.Client{
+				ID:                   "password-client",
+				Scope:                []string{"openid"},
+				AuthorizedGrantTypes: []string{"password", "refresh_token"},
+			}
+			err := warrant.NewClientsService(config).Create(client, "", adminToken)
+			Expect(err).NotTo(HaveOccurred())
+
+			user, err = warrant.NewUsersService(config).Create("username", "user@example.com", adminToken)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("retrieves and refreshes tokens for the user", func() {
+			source := warrant.NewPasswordTokenSource(config, "username", "password", client)
+			source.ExpiryDelta = 24 * time.Hour
+
+			token, err := source.Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			refreshedToken, err := source.Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refreshedToken).NotTo(Equal(token))
+
+			decodedToken, err := warrant.NewTokensService(config).Decode(refreshedToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.UserID).To(Equal(user.ID))
+		})
+	})
+
+	Describe("NewRefreshTokenSource", func() {
+		It("exchanges the refresh token for access tokens", func() {
+			client := warrant.Client{
+				ID:                   "refresh-client",
+				Scope:                []string{"openid"},
+				AuthorizedGrantTypes: []string{"password", "refresh_token"},
+			}
+			err := warrant.NewClientsService(config).Create(client, "secret", adminToken)
+			Expect(err).NotTo(HaveOccurred())
+
+			usersService := warrant.NewUsersService(config)
+			user, err := usersService.Create("username", "user@example.com", adminToken)
+			Expect(err).NotTo(HaveOccurred())
+
+			response, err := usersService.GetTokenResponse("username", "password", client)
+			Expect(err).NotTo(HaveOccurred())
+
+			source := warrant.NewRefreshTokenSource(config, response.RefreshToken, "refresh-client", "secret")
+
+			token, err := source.Token(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			decodedToken, err := warrant.NewTokensService(config).Decode(token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.UserID).To(Equal(user.ID))
+		})
+
+		It("returns an error when the refresh token is invalid", func() {
+			source := warrant.NewRefreshTokenSource(config, "invalid-refresh-token", "admin", "admin")
+
+			_, err := source.Token(context.Background())
+			Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+		})
+
+		It("returns an error when the refresh token is empty", func() {
+			source := warrant.NewRefreshTokenSource(config, "", "admin", "admin")
+
+			_, err := source.Token(context.Background())
+			Expect(err).To(MatchError("token source has no refresh token or credentials with which to retrieve a token"))
+		})
+	})
+
+	Describe("RefreshingTokenSource", func() {
+		It("returns an error when it is not created by a constructor", func() {
+			var source warrant.RefreshingTokenSource
+
+			_, err := source.Token(context.Background())
+			Expect(err).To(MatchError("token source has no refresh token or credentials with which to retrieve a token"))
+		})
+	})
+
+	Context("when the config has a token source", func() {
+		It("uses the token source for requests made without a token", func() {
+			config.TokenSource = warrant.NewClientCredentialsTokenSource(config, "admin", "admin")
+			service := warrant.NewUsersService(config)
+
+			user, err := service.Create("username", "user@example.com", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			fetchedUser, err := service.Get(user.ID, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetchedUser.ID).To(Equal(user.ID))
+		})
+
+		It("prefers an explicitly given token", func() {
+			config.TokenSource = warrant.StaticTokenSource("invalid-token")
+			service := warrant.NewUsersService(config)
+
+			_, err := service.Create("username", "user@example.com", adminToken)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
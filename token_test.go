@@ -1,10 +1,15 @@
 package warrant_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"fmt"
+	"time"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/pivotal-cf-experimental/warrant"
@@ -21,6 +26,16 @@ type SigningKey struct {
 	SharedSecret string
 }
 
+func encodePublicKey(publicKey interface{}) string {
+	publicASN1, err := x509.MarshalPKIXPublicKey(publicKey)
+	Expect(err).NotTo(HaveOccurred())
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: publicASN1,
+	}))
+}
+
 var _ = Describe("Token", func() {
 	var (
 		keyA, keyB, keyC SigningKey
@@ -202,6 +217,115 @@ var _ = Describe("Token", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		Context("when the signing key uses ECDSA", func() {
+			for _, example := range []struct {
+				method *jwt.SigningMethodECDSA
+				curve  elliptic.Curve
+			}{
+				{jwt.SigningMethodES256, elliptic.P256()},
+				{jwt.SigningMethodES384, elliptic.P384()},
+				{jwt.SigningMethodES512, elliptic.P521()},
+			} {
+				method, curve := example.method, example.curve
+
+				It(fmt.Sprintf("verifies tokens signed using %s", method.Alg()), func() {
+					privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+					Expect(err).NotTo(HaveOccurred())
+
+					unsignedToken := jwt.NewWithClaims(method, jwt.MapClaims{
+						"client_id": "some-client-id",
+						"user_id":   "some-user-id",
+						"scope":     []string{"some-scope"},
+						"iss":       "some-issuer",
+					})
+
+					unsignedToken.Header["kid"] = "some-ecdsa-key-id"
+
+					signedToken, err := unsignedToken.SignedString(privateKey)
+					Expect(err).NotTo(HaveOccurred())
+
+					token, err := service.Decode(signedToken)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(token.Algorithm).To(Equal(method.Alg()))
+
+					err = token.Verify([]warrant.SigningKey{
+						{
+							KeyId:     keyA.ID,
+							Algorithm: keyA.Algorithm,
+							Value:     keyA.PublicKey,
+						},
+						{
+							KeyId:     "some-ecdsa-key-id",
+							Algorithm: method.Alg(),
+							Value:     encodePublicKey(&privateKey.PublicKey),
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+				})
+			}
+		})
+
+		Context("when the signing key uses RSA-PSS", func() {
+			It("verifies the token", func() {
+				unsignedToken := jwt.NewWithClaims(jwt.SigningMethodPS256, jwt.MapClaims{
+					"client_id": "some-client-id",
+					"user_id":   "some-user-id",
+					"scope":     []string{"some-scope"},
+					"iss":       "some-issuer",
+				})
+
+				unsignedToken.Header["kid"] = keyA.ID
+
+				signedToken, err := unsignedToken.SignedString(keyA.PrivateKey)
+				Expect(err).NotTo(HaveOccurred())
+
+				token, err := service.Decode(signedToken)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(token.Algorithm).To(Equal("PS256"))
+
+				err = token.Verify([]warrant.SigningKey{
+					{
+						KeyId:     keyA.ID,
+						Algorithm: "PS256",
+						Value:     keyA.PublicKey,
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the signing key uses EdDSA", func() {
+			It("verifies the token", func() {
+				publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				unsignedToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+					"client_id": "some-client-id",
+					"user_id":   "some-user-id",
+					"scope":     []string{"some-scope"},
+					"iss":       "some-issuer",
+				})
+
+				unsignedToken.Header["kid"] = "some-eddsa-key-id"
+
+				signedToken, err := unsignedToken.SignedString(privateKey)
+				Expect(err).NotTo(HaveOccurred())
+
+				token, err := service.Decode(signedToken)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(token.Algorithm).To(Equal("EdDSA"))
+
+				err = token.Verify([]warrant.SigningKey{
+					{
+						KeyId:     "some-eddsa-key-id",
+						Algorithm: "EdDSA",
+						Value:     encodePublicKey(publicKey),
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
 		Context("failure cases", func() {
 			Context("when the token was not signed by a known signing key", func() {
 				It("returns an error", func() {
@@ -291,6 +415,152 @@ var _ = Describe("Token", func() {
 				})
 			})
 
+			Context("when the token is signed using HMAC with the public key", func() {
+				It("returns an error", func() {
+					unsignedToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+						"client_id": "some-client-id",
+						"user_id":   "some-user-id",
+						"scope":     []string{"some-scope"},
+						"iss":       "some-issuer",
+					})
+
+					unsignedToken.Header["kid"] = keyA.ID
+
+					signedToken, err := unsignedToken.SignedString([]byte(keyA.PublicKey))
+					Expect(err).NotTo(HaveOccurred())
+
+					token, err := service.Decode(signedToken)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = token.Verify([]warrant.SigningKey{
+						{
+							KeyId:     keyA.ID,
+							Algorithm: keyA.Algorithm,
+							Value:     keyA.PublicKey,
+						},
+					})
+					Expect(err).To(MatchError(`token signing method HS256 does not match the RS256 algorithm of key "some-key-id-a"`))
+
+					By("omitting the algorithm of the signing key", func() {
+						err = token.Verify([]warrant.SigningKey{
+							{
+								KeyId: keyA.ID,
+								Value: keyA.PublicKey,
+							},
+						})
+						Expect(err).To(MatchError("token signing method HS256 requires a key with an HMAC algorithm"))
+					})
+
+					By("claiming an HMAC algorithm for the public key", func() {
+						err = token.Verify([]warrant.SigningKey{
+							{
+								KeyId:     keyA.ID,
+								Algorithm: "HS256",
+								Value:     keyA.PublicKey,
+							},
+						})
+						Expect(err).To(MatchError(`token signing method HS256 cannot be used with the public key "some-key-id-a"`))
+					})
+				})
+			})
+
+			Context("when the token algorithm does not match the signing key", func() {
+				It("returns an error", func() {
+					unsignedToken := jwt.NewWithClaims(jwt.SigningMethodRS384, jwt.MapClaims{
+						"client_id": "some-client-id",
+						"user_id":   "some-user-id",
+						"scope":     []string{"some-scope"},
+						"iss":       "some-issuer",
+					})
+
+					unsignedToken.Header["kid"] = keyA.ID
+
+					signedToken, err := unsignedToken.SignedString(keyA.PrivateKey)
+					Expect(err).NotTo(HaveOccurred())
+
+					token, err := service.Decode(signedToken)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = token.Verify([]warrant.SigningKey{
+						{
+							KeyId:     keyA.ID,
+							Algorithm: "SHA256withRSA",
+							Value:     keyA.PublicKey,
+						},
+					})
+					Expect(err).To(MatchError(`token signing method RS384 does not match the SHA256withRSA algorithm of key "some-key-id-a"`))
+				})
+			})
+
+			Context("when the token algorithm does not suit the type of the public key", func() {
+				It("returns an error", func() {
+					privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+					Expect(err).NotTo(HaveOccurred())
+
+					unsignedToken := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+						"client_id": "some-client-id",
+						"user_id":   "some-user-id",
+						"scope":     []string{"some-scope"},
+						"iss":       "some-issuer",
+					})
+
+					unsignedToken.Header["kid"] = keyA.ID
+
+					signedToken, err := unsignedToken.SignedString(privateKey)
+					Expect(err).NotTo(HaveOccurred())
+
+					token, err := service.Decode(signedToken)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = token.Verify([]warrant.SigningKey{
+						{
+							KeyId: keyA.ID,
+							Value: keyA.PublicKey,
+						},
+					})
+					Expect(err).To(MatchError(`token signing method ES256 cannot be used with the public key "some-key-id-a"`))
+
+					By("claiming a curve that does not match the public key", func() {
+						token.Algorithm = "ES384"
+
+						err = token.Verify([]warrant.SigningKey{
+							{
+								KeyId: keyA.ID,
+								Value: encodePublicKey(&privateKey.PublicKey),
+							},
+						})
+						Expect(err).To(MatchError(`token signing method ES384 cannot be used with the public key "some-key-id-a"`))
+					})
+				})
+			})
+
+			Context("when the HMAC signing key does not have a value", func() {
+				It("returns an error", func() {
+					unsignedToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+						"client_id": "some-client-id",
+						"user_id":   "some-user-id",
+						"scope":     []string{"some-scope"},
+						"iss":       "some-issuer",
+					})
+
+					unsignedToken.Header["kid"] = keyC.ID
+
+					signedToken, err := unsignedToken.SignedString([]byte{})
+					Expect(err).NotTo(HaveOccurred())
+
+					token, err := service.Decode(signedToken)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = token.Verify([]warrant.SigningKey{
+						{
+							KeyId:     keyC.ID,
+							Algorithm: keyC.Algorithm,
+						},
+					})
+					Expect(err).To(MatchError(`key "some-key-id-c" does not have a value`))
+				})
+			})
+
 			Context("when the token algorithm is not supported", func() {
 				It("returns an error", func() {
 					unsignedToken := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
@@ -320,4 +590,109 @@ var _ = Describe("Token", func() {
 			})
 		})
 	})
+
+	Describe("Validate", func() {
+		var (
+			token warrant.Token
+			now   time.Time
+		)
+
+		BeforeEach(func() {
+			now = time.Unix(1500000000, 0)
+			token = warrant.Token{
+				Scopes:    []string{"scim.read", "openid"},
+				Audiences: []string{"scim", "openid"},
+				Issuer:    "https://uaa.example.com/oauth/token",
+				IssuedAt:  now.Add(-time.Minute),
+				NotBefore: now.Add(-time.Minute),
+				ExpiresAt: now.Add(time.Minute),
+			}
+		})
+
+		It("validates the claims of the token", func() {
+			err := token.Validate(warrant.TokenValidation{
+				Time:      now,
+				Audiences: []string{"scim"},
+				Scopes:    []string{"scim.read"},
+				Issuer:    "https://uaa.example.com/oauth/token",
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("does not check time-based claims that are not present", func() {
+			err := warrant.Token{}.Validate(warrant.TokenValidation{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("allows for clock skew", func() {
+			err := token.Validate(warrant.TokenValidation{
+				Time:      now.Add(90 * time.Second),
+				ClockSkew: time.Minute,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = token.Validate(warrant.TokenValidation{
+				Time:      now.Add(-90 * time.Second),
+				ClockSkew: time.Minute,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the token has expired", func() {
+				err := token.Validate(warrant.TokenValidation{
+					Time: now.Add(time.Minute),
+				})
+				Expect(err).To(Equal(warrant.TokenExpiredError{ExpiresAt: token.ExpiresAt}))
+				Expect(err).To(MatchError(fmt.Sprintf("token expired at %s", token.ExpiresAt.Format(time.RFC3339))))
+			})
+
+			It("returns an error when the token is not yet valid", func() {
+				token.IssuedAt = time.Time{}
+
+				err := token.Validate(warrant.TokenValidation{
+					Time: now.Add(-2 * time.Minute),
+				})
+				Expect(err).To(Equal(warrant.TokenNotYetValidError{NotBefore: token.NotBefore}))
+			})
+
+			It("returns an error when the token was issued in the future", func() {
+				token.NotBefore = time.Time{}
+
+				err := token.Validate(warrant.TokenValidation{
+					Time: now.Add(-2 * time.Minute),
+				})
+				Expect(err).To(Equal(warrant.TokenIssuedInFutureError{IssuedAt: token.IssuedAt}))
+			})
+
+			It("returns an error when the issuer does not match", func() {
+				err := token.Validate(warrant.TokenValidation{
+					Time:   now,
+					Issuer: "https://other.example.com/oauth/token",
+				})
+				Expect(err).To(Equal(warrant.TokenIssuerError{
+					Issuer:   "https://uaa.example.com/oauth/token",
+					Expected: "https://other.example.com/oauth/token",
+				}))
+			})
+
+			It("returns an error when a required audience is missing", func() {
+				err := token.Validate(warrant.TokenValidation{
+					Time:      now,
+					Audiences: []string{"scim", "cloud_controller"},
+				})
+				Expect(err).To(Equal(warrant.TokenAudienceError{Audience: "cloud_controller"}))
+				Expect(err).To(MatchError(`token is missing required audience "cloud_controller"`))
+			})
+
+			It("returns an error when a required scope is missing", func() {
+				err := token.Validate(warrant.TokenValidation{
+					Time:   now,
+					Scopes: []string{"scim.write"},
+				})
+				Expect(err).To(Equal(warrant.TokenScopeError{Scope: "scim.write"}))
+				Expect(err).To(MatchError(`token is missing required scope "scim.write"`))
+			})
+		})
+	})
 })
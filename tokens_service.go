@@ -1,10 +1,13 @@
 package warrant
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/pivotal-cf-experimental/warrant/internal/documents"
@@ -44,12 +47,12 @@ func NewTokensService(config Config) TokensService {
 func (ts TokensService) Decode(token string) (Token, error) {
 	segments := strings.Split(token, ".")
 	if len(segments) != 3 {
-		return Token{}, InvalidTokenError{fmt.Errorf("invalid number of segments in token (%d/3)", len(segments))}
+		return Token{}, InvalidTokenError{err: fmt.Errorf("invalid number of segments in token (%d/3)", len(segments))}
 	}
 
 	headerSegment, err := jwt.DecodeSegment(segments[0])
 	if err != nil {
-		return Token{}, InvalidTokenError{fmt.Errorf("header cannot be decoded: %s", err)}
+		return Token{}, InvalidTokenError{err: fmt.Errorf("header cannot be decoded: %s", err)}
 	}
 
 	var header struct {
@@ -58,12 +61,12 @@ func (ts TokensService) Decode(token string) (Token, error) {
 	}
 	err = json.Unmarshal(headerSegment, &header)
 	if err != nil {
-		return Token{}, InvalidTokenError{fmt.Errorf("header cannot be parsed: %s", err)}
+		return Token{}, InvalidTokenError{err: fmt.Errorf("header cannot be parsed: %s", err)}
 	}
 
 	claims, err := jwt.DecodeSegment(segments[1])
 	if err != nil {
-		return Token{}, InvalidTokenError{fmt.Errorf("claims cannot be decoded: %s", err)}
+		return Token{}, InvalidTokenError{err: fmt.Errorf("claims cannot be decoded: %s", err)}
 	}
 
 	t := Token{
@@ -75,21 +78,75 @@ func (ts TokensService) Decode(token string) (Token, error) {
 			Signature: segments[2],
 		},
 	}
-	err = json.Unmarshal(claims, &t)
+	err = unmarshalClaims(claims, &t)
 	if err != nil {
-		return Token{}, InvalidTokenError{fmt.Errorf("token cannot be parsed: %s", err)}
+		return Token{}, InvalidTokenError{err: fmt.Errorf("token cannot be parsed: %s", err)}
 	}
 
 	return t, nil
 }
 
+// unmarshalClaims populates the given token with the claims in the given JSON
+// object, including the registered claims that require conversion.
+func unmarshalClaims(claims []byte, t *Token) error {
+	err := json.Unmarshal(claims, t)
+	if err != nil {
+		return err
+	}
+
+	var registeredClaims struct {
+		Audience  interface{} `json:"aud"`
+		ExpiresAt float64     `json:"exp"`
+		IssuedAt  float64     `json:"iat"`
+		NotBefore float64     `json:"nbf"`
+	}
+	err = json.Unmarshal(claims, &registeredClaims)
+	if err != nil {
+		return err
+	}
+
+	switch audience := registeredClaims.Audience.(type) {
+	case string:
+		t.Audiences = strings.Fields(audience)
+	case []interface{}:
+		for _, a := range audience {
+			value, ok := a.(string)
+			if !ok {
+				return fmt.Errorf("invalid audience %v", a)
+			}
+
+			t.Audiences = append(t.Audiences, value)
+		}
+	}
+
+	t.ExpiresAt = claimTime(registeredClaims.ExpiresAt)
+	t.IssuedAt = claimTime(registeredClaims.IssuedAt)
+	t.NotBefore = claimTime(registeredClaims.NotBefore)
+
+	return nil
+}
+
+func claimTime(seconds float64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(seconds), 0)
+}
+
 // GetSigningKey makes a request to UAA to retrieve the SigningKey used to
 // generate valid tokens.
 func (ts TokensService) GetSigningKey() (SigningKey, error) {
+	return ts.GetSigningKeyWithContext(context.Background())
+}
+
+// GetSigningKeyWithContext is like GetSigningKey, but uses the given context for the request to UAA.
+func (ts TokensService) GetSigningKeyWithContext(ctx context.Context) (SigningKey, error) {
 	resp, err := newNetworkClient(ts.config).MakeRequest(network.Request{
 		Method:                "GET",
 		Path:                  "/token_key",
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return SigningKey{}, translateError(err)
@@ -101,22 +158,32 @@ func (ts TokensService) GetSigningKey() (SigningKey, error) {
 		return SigningKey{}, MalformedResponseError{err}
 	}
 
-	key := SigningKey{
-		KeyId:     response.Kid,
-		Algorithm: response.Alg,
-		Value:     response.Value,
+	key, err := newSigningKey(response)
+	if err != nil {
+		return SigningKey{}, MalformedResponseError{err}
 	}
 
 	return key, nil
 }
 
 // GetSigningKeys makes a request to UAA to retrieve the SigningKeys used to
-// generate valid tokens.
+// generate valid tokens. Keys that are published only in the JSON Web Key
+// format, as by identity providers other than UAA, are given a PEM encoded
+// Value derived from their RSA, EC, or OKP key parameters. Keys whose
+// parameters are invalid or use an unsupported curve are skipped, so that
+// the remaining keys can still be used to verify tokens. A MalformedResponseError
+// is returned when none of the keys can be parsed.
 func (ts TokensService) GetSigningKeys() ([]SigningKey, error) {
+	return ts.GetSigningKeysWithContext(context.Background())
+}
+
+// GetSigningKeysWithContext is like GetSigningKeys, but uses the given context for the request to UAA.
+func (ts TokensService) GetSigningKeysWithContext(ctx context.Context) ([]SigningKey, error) {
 	resp, err := newNetworkClient(ts.config).MakeRequest(network.Request{
 		Method:                "GET",
 		Path:                  "/token_keys",
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return []SigningKey{}, translateError(err)
@@ -130,13 +197,133 @@ func (ts TokensService) GetSigningKeys() ([]SigningKey, error) {
 
 	signingKeys := make([]SigningKey, 0, len(response.Keys))
 
-	for _, key := range response.Keys {
-		signingKeys = append(signingKeys, SigningKey{
-			KeyId:     key.Kid,
-			Algorithm: key.Alg,
-			Value:     key.Value,
-		})
+	var keyErr error
+	for _, document := range response.Keys {
+		key, err := newSigningKey(document)
+		if err != nil {
+			keyErr = err
+			continue
+		}
+
+		signingKeys = append(signingKeys, key)
+	}
+
+	if len(signingKeys) == 0 && keyErr != nil {
+		return []SigningKey{}, MalformedResponseError{keyErr}
 	}
 
 	return signingKeys, nil
 }
+
+// RefreshToken will make a request to UAA to exchange the given refresh token for a new access
+// token using the "refresh_token" grant type. The id and secret of the client to which the refresh
+// token was granted are required.
+func (ts TokensService) RefreshToken(refreshToken, clientID, clientSecret string) (TokenResponse, error) {
+	return ts.RefreshTokenWithContext(context.Background(), refreshToken, clientID, clientSecret)
+}
+
+// RefreshTokenWithContext is like RefreshToken, but uses the given context for the request to UAA.
+func (ts TokensService) RefreshTokenWithContext(ctx context.Context, refreshToken, clientID, clientSecret string) (TokenResponse, error) {
+	resp, err := newNetworkClient(ts.config).MakeRequest(network.Request{
+		Method:        "POST",
+		Path:          "/oauth/token",
+		Authorization: network.NewBasicAuthorization(clientID, clientSecret),
+		Body: network.NewFormRequestBody(url.Values{
+			"client_id":     []string{clientID},
+			"grant_type":    []string{"refresh_token"},
+			"refresh_token": []string{refreshToken},
+		}),
+		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
+	})
+	if err != nil {
+		return TokenResponse{}, translateError(err)
+	}
+
+	var response documents.TokenResponse
+	err = json.Unmarshal(resp.Body, &response)
+	if err != nil {
+		return TokenResponse{}, MalformedResponseError{err}
+	}
+
+	return newTokenResponseFromDocument(response), nil
+}
+
+// CheckToken makes a request to UAA to check whether the given token is still
+// active. The id and secret of a client with the "uaa.resource" authority are
+// required. A token that UAA reports as invalid, such as one that has expired
+// or been revoked, results in an inactive CheckTokenResponse rather than an error.
+func (ts TokensService) CheckToken(token, clientID, clientSecret string) (CheckTokenResponse, error) {
+	return ts.CheckTokenWithContext(context.Background(), token, clientID, clientSecret)
+}
+
+// CheckTokenWithContext is like CheckToken, but uses the given context for the request to UAA.
+func (ts TokensService) CheckTokenWithContext(ctx context.Context, token, clientID, clientSecret string) (CheckTokenResponse, error) {
+	resp, err := newNetworkClient(ts.config).MakeRequest(network.Request{
+		Method:        "POST",
+		Path:          "/check_token",
+		Authorization: network.NewBasicAuthorization(clientID, clientSecret),
+		Body: network.NewFormRequestBody(url.Values{
+			"token": []string{token},
+		}),
+		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
+	})
+	if err != nil {
+		if s, ok := err.(network.UnexpectedStatusError); ok && s.Status == http.StatusBadRequest {
+			var response documents.ErrorResponse
+			if json.Unmarshal(s.Body, &response) == nil && response.Error == "invalid_token" {
+				return CheckTokenResponse{}, nil
+			}
+		}
+
+		return CheckTokenResponse{}, translateError(err)
+	}
+
+	var response struct {
+		Active *bool `json:"active"`
+	}
+	err = json.Unmarshal(resp.Body, &response)
+	if err != nil {
+		return CheckTokenResponse{}, MalformedResponseError{err}
+	}
+
+	var t Token
+	err = unmarshalClaims(resp.Body, &t)
+	if err != nil {
+		return CheckTokenResponse{}, MalformedResponseError{err}
+	}
+
+	return CheckTokenResponse{
+		Active: response.Active == nil || *response.Active,
+		Token:  t,
+	}, nil
+}
+
+// RevokeToken will make a request to UAA to revoke the token with the given unique identifier, as found
+// in the "jti" claim of the token. A token with the "tokens.revoke" scope or the "uaa.admin"
+// authority is required.
+func (ts TokensService) RevokeToken(tokenID string, token string) error {
+	return ts.RevokeTokenWithContext(context.Background(), tokenID, token)
+}
+
+// RevokeTokenWithContext is like RevokeToken, but uses the given context for the request to UAA.
+func (ts TokensService) RevokeTokenWithContext(ctx context.Context, tokenID string, token string) error {
+	authorization, err := tokenAuthorization(ctx, ts.config, token)
+	if err != nil {
+		return err
+	}
+
+	_, err = newNetworkClient(ts.config).MakeRequest(network.Request{
+		Method:                "DELETE",
+		Path:                  fmt.Sprintf("/oauth/token/revoke/%s", tokenID),
+		Authorization:         authorization,
+		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
+	})
+	if err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
@@ -1,8 +1,19 @@
 package warrant_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"time"
+
+	"github.com/golang-jwt/jwt"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -47,6 +58,41 @@ var _ = Describe("TokensService", func() {
 			}))
 		})
 
+		It("decodes the registered claims of the token", func() {
+			issuedAt := time.Unix(1500000000, 0)
+			encodedToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"client_id":   "some-client-id",
+				"authorities": []string{"scim.read"},
+				"aud":         []string{"scim", "cloud_controller"},
+				"jti":         "some-token-id",
+				"iat":         issuedAt.Unix(),
+				"nbf":         issuedAt.Unix(),
+				"exp":         issuedAt.Add(time.Hour).Unix(),
+			}).SignedString([]byte("secret"))
+			Expect(err).NotTo(HaveOccurred())
+
+			token, err := service.Decode(encodedToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.ClientID).To(Equal("some-client-id"))
+			Expect(token.Authorities).To(Equal([]string{"scim.read"}))
+			Expect(token.Audiences).To(Equal([]string{"scim", "cloud_controller"}))
+			Expect(token.JTI).To(Equal("some-token-id"))
+			Expect(token.IssuedAt).To(Equal(issuedAt))
+			Expect(token.NotBefore).To(Equal(issuedAt))
+			Expect(token.ExpiresAt).To(Equal(issuedAt.Add(time.Hour)))
+		})
+
+		It("decodes a single audience given as a string", func() {
+			encodedToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"aud": "scim",
+			}).SignedString([]byte("secret"))
+			Expect(err).NotTo(HaveOccurred())
+
+			token, err := service.Decode(encodedToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.Audiences).To(Equal([]string{"scim"}))
+		})
+
 		Context("failure cases", func() {
 			Context("when there is an invalid number of segments", func() {
 				It("returns an error", func() {
@@ -158,6 +204,173 @@ var _ = Describe("TokensService", func() {
 			}))
 		})
 
+		Context("when the keys are only published in the JSON Web Key format", func() {
+			It("derives the PEM encoded public keys", func() {
+				rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+				Expect(err).NotTo(HaveOccurred())
+
+				ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				ed25519Key, _, err := ed25519.GenerateKey(rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				encode := base64.RawURLEncoding.EncodeToString
+
+				jwkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					json.NewEncoder(w).Encode(map[string]interface{}{
+						"keys": []map[string]string{
+							{
+								"kid": "some-rsa-key",
+								"alg": "RS256",
+								"kty": "RSA",
+								"n":   encode(rsaKey.N.Bytes()),
+								"e":   encode(big.NewInt(int64(rsaKey.E)).Bytes()),
+							},
+							{
+								"kid": "some-ecdsa-key",
+								"alg": "ES256",
+								"kty": "EC",
+								"crv": "P-256",
+								"x":   encode(ecdsaKey.X.FillBytes(make([]byte, 32))),
+								"y":   encode(ecdsaKey.Y.FillBytes(make([]byte, 32))),
+							},
+							{
+								"kid": "some-ed25519-key",
+								"alg": "EdDSA",
+								"kty": "OKP",
+								"crv": "Ed25519",
+								"x":   encode(ed25519Key),
+							},
+						},
+					})
+				}))
+				defer jwkServer.Close()
+
+				service = warrant.NewTokensService(warrant.Config{
+					Host:          jwkServer.URL,
+					SkipVerifySSL: true,
+					TraceWriter:   TraceWriter,
+				})
+
+				keys, err := service.GetSigningKeys()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(keys).To(Equal([]warrant.SigningKey{
+					{
+						KeyId:     "some-rsa-key",
+						Algorithm: "RS256",
+						Value:     encodePublicKey(&rsaKey.PublicKey),
+					},
+					{
+						KeyId:     "some-ecdsa-key",
+						Algorithm: "ES256",
+						Value:     encodePublicKey(&ecdsaKey.PublicKey),
+					},
+					{
+						KeyId:     "some-ed25519-key",
+						Algorithm: "EdDSA",
+						Value:     encodePublicKey(ed25519Key),
+					},
+				}))
+			})
+		})
+
+		Context("when the JSON Web Keys do not name their algorithm", func() {
+			It("infers the algorithm from the type of the key", func() {
+				rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+				Expect(err).NotTo(HaveOccurred())
+
+				ecdsaKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				ed25519Key, _, err := ed25519.GenerateKey(rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				encode := base64.RawURLEncoding.EncodeToString
+
+				jwkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					json.NewEncoder(w).Encode(map[string]interface{}{
+						"keys": []map[string]string{
+							{
+								"kid": "some-rsa-key",
+								"kty": "RSA",
+								"n":   encode(rsaKey.N.Bytes()),
+								"e":   encode(big.NewInt(int64(rsaKey.E)).Bytes()),
+							},
+							{
+								"kid": "some-ecdsa-key",
+								"kty": "EC",
+								"crv": "P-384",
+								"x":   encode(ecdsaKey.X.FillBytes(make([]byte, 48))),
+								"y":   encode(ecdsaKey.Y.FillBytes(make([]byte, 48))),
+							},
+							{
+								"kid": "some-ed25519-key",
+								"kty": "OKP",
+								"crv": "Ed25519",
+								"x":   encode(ed25519Key),
+							},
+						},
+					})
+				}))
+				defer jwkServer.Close()
+
+				service = warrant.NewTokensService(warrant.Config{
+					Host:          jwkServer.URL,
+					SkipVerifySSL: true,
+					TraceWriter:   TraceWriter,
+				})
+
+				keys, err := service.GetSigningKeys()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(keys).To(Equal([]warrant.SigningKey{
+					{
+						KeyId: "some-rsa-key",
+						Value: encodePublicKey(&rsaKey.PublicKey),
+					},
+					{
+						KeyId:     "some-ecdsa-key",
+						Algorithm: "ES384",
+						Value:     encodePublicKey(&ecdsaKey.PublicKey),
+					},
+					{
+						KeyId:     "some-ed25519-key",
+						Algorithm: "EdDSA",
+						Value:     encodePublicKey(ed25519Key),
+					},
+				}))
+			})
+		})
+
+		Context("when a JSON Web Key cannot be parsed", func() {
+			It("skips the key", func() {
+				jwkServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					w.Write([]byte(`{"keys":[
+						{"kid":"some-invalid-key","alg":"ES256","kty":"EC","crv":"P-256","x":"AQ","y":"AQ"},
+						{"kid":"some-unsupported-key","alg":"ES256K","kty":"EC","crv":"secp256k1","x":"AQ","y":"AQ"},
+						{"kid":"some-key","alg":"SHA256withRSA","value":"some-public-key"}
+					]}`))
+				}))
+				defer jwkServer.Close()
+
+				service = warrant.NewTokensService(warrant.Config{
+					Host:          jwkServer.URL,
+					SkipVerifySSL: true,
+					TraceWriter:   TraceWriter,
+				})
+
+				keys, err := service.GetSigningKeys()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(keys).To(Equal([]warrant.SigningKey{
+					{
+						KeyId:     "some-key",
+						Algorithm: "SHA256withRSA",
+						Value:     "some-public-key",
+					},
+				}))
+			})
+		})
+
 		Context("failure cases", func() {
 			It("returns an error if the HTTP request fails", func() {
 				erroringServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -189,6 +402,228 @@ var _ = Describe("TokensService", func() {
 				Expect(err).To(BeAssignableToTypeOf(warrant.MalformedResponseError{}))
 				Expect(err).To(MatchError("malformed response: invalid character 'h' in literal true (expecting 'r')"))
 			})
+
+			It("returns an error if none of the JSON Web Keys can be parsed", func() {
+				invalidKeyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					w.Write([]byte(`{"keys":[{"kid":"some-key","alg":"ES256","kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}]}`))
+				}))
+				defer invalidKeyServer.Close()
+
+				service = warrant.NewTokensService(warrant.Config{
+					Host:          invalidKeyServer.URL,
+					SkipVerifySSL: true,
+					TraceWriter:   TraceWriter,
+				})
+
+				_, err := service.GetSigningKeys()
+				Expect(err).To(BeAssignableToTypeOf(warrant.MalformedResponseError{}))
+				Expect(err).To(MatchError(`malformed response: key "some-key" is not a point on the P-256 curve`))
+			})
+		})
+	})
+
+	Describe("RefreshToken", func() {
+		var (
+			refreshToken string
+			user         warrant.User
+		)
+
+		BeforeEach(func() {
+			clientsService := warrant.NewClientsService(config)
+			usersService := warrant.NewUsersService(config)
+
+			adminToken, err := clientsService.GetToken("admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+
+			client := warrant.Client{
+				ID:                   "refreshing-client",
+				Scope:                []string{"openid"},
+				AuthorizedGrantTypes: []string{"password", "refresh_token"},
+			}
+			err = clientsService.Create(client, "secret", adminToken)
+			Expect(err).NotTo(HaveOccurred())
+
+			user, err = usersService.Create("username", "user@example.com", adminToken)
+			Expect(err).NotTo(HaveOccurred())
+
+			response, err := usersService.GetTokenResponse("username", "password", client)
+			Expect(err).NotTo(HaveOccurred())
+
+			refreshToken = response.RefreshToken
+		})
+
+		It("exchanges the refresh token for a new access token", func() {
+			response, err := service.RefreshToken(refreshToken, "refreshing-client", "secret")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.RefreshToken).To(Equal(refreshToken))
+			Expect(response.Scopes).To(Equal([]string{"openid"}))
+
+			token, err := service.Decode(response.AccessToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.UserID).To(Equal(user.ID))
+			Expect(token.ClientID).To(Equal("refreshing-client"))
+			Expect(token.Issuer).To(Equal(fakeUAA.URL() + "/oauth/token"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the refresh token is invalid", func() {
+				_, err := service.RefreshToken("not-a-refresh-token", "refreshing-client", "secret")
+				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+			})
+
+			It("returns an error when an access token is given in place of a refresh token", func() {
+				accessToken := fakeUAA.UserTokenFor(user.ID, []string{"openid"}, []string{})
+
+				_, err := service.RefreshToken(accessToken, "refreshing-client", "secret")
+				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+			})
+
+			It("rejects the refresh token when it is used as a bearer token", func() {
+				_, err := warrant.NewClientsService(config).List(warrant.Query{}, refreshToken)
+				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err.(warrant.UnauthorizedError).Code).To(Equal("invalid_token"))
+
+				err = warrant.NewUsersService(config).ChangePassword(user.ID, "password", "new-password", refreshToken)
+				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+			})
+
+			It("returns an error when the refresh token was granted to another client", func() {
+				_, err := service.RefreshToken(refreshToken, "admin", "admin")
+				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+			})
+
+			It("returns an error if the response JSON cannot be parsed", func() {
+				malformedJSONServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					w.Write([]byte("this is not JSON"))
+				}))
+
+				service = warrant.NewTokensService(warrant.Config{
+					Host:          malformedJSONServer.URL,
+					SkipVerifySSL: true,
+					TraceWriter:   TraceWriter,
+				})
+
+				_, err := service.RefreshToken(refreshToken, "refreshing-client", "secret")
+				Expect(err).To(BeAssignableToTypeOf(warrant.MalformedResponseError{}))
+			})
+		})
+	})
+
+	Describe("CheckToken", func() {
+		var (
+			clientToken string
+			user        warrant.User
+		)
+
+		BeforeEach(func() {
+			clientsService := warrant.NewClientsService(config)
+
+			var err error
+			clientToken, err = clientsService.GetToken("admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+
+			user, err = warrant.NewUsersService(config).Create("username", "user@example.com", clientToken)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the claims of an active token", func() {
+			response, err := service.CheckToken(clientToken, "admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Active).To(BeTrue())
+			Expect(response.Token.ClientID).To(Equal("admin"))
+			Expect(response.Token.Authorities).To(ContainElement("scim.write"))
+			Expect(response.Token.Audiences).To(ContainElement("scim"))
+			Expect(response.Token.JTI).NotTo(BeEmpty())
+			Expect(response.Token.ExpiresAt).To(BeTemporally(">", time.Now()))
+		})
+
+		It("returns the user of a user token", func() {
+			userToken := fakeUAA.UserTokenFor(user.ID, []string{"openid"}, []string{"openid"})
+
+			response, err := service.CheckToken(userToken, "admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Active).To(BeTrue())
+			Expect(response.Token.UserID).To(Equal(user.ID))
+			Expect(response.Token.Scopes).To(Equal([]string{"openid"}))
+		})
+
+		It("reports an invalid token as inactive", func() {
+			response, err := service.CheckToken("not-a-token", "admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response).To(Equal(warrant.CheckTokenResponse{}))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the client credentials are invalid", func() {
+				_, err := service.CheckToken(clientToken, "admin", "wrong-secret")
+				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+			})
+
+			It("returns an error when the client does not have the uaa.resource authority", func() {
+				client := warrant.Client{
+					ID:          "some-client",
+					Authorities: []string{"scim.read"},
+				}
+
+				err := warrant.NewClientsService(config).Create(client, "secret", clientToken)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = service.CheckToken(clientToken, "some-client", "secret")
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
+			})
+
+			It("returns an error if the response JSON cannot be parsed", func() {
+				malformedJSONServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					w.Write([]byte("this is not JSON"))
+				}))
+
+				service = warrant.NewTokensService(warrant.Config{
+					Host:          malformedJSONServer.URL,
+					SkipVerifySSL: true,
+					TraceWriter:   TraceWriter,
+				})
+
+				_, err := service.CheckToken(clientToken, "admin", "admin")
+				Expect(err).To(BeAssignableToTypeOf(warrant.MalformedResponseError{}))
+			})
+		})
+	})
+
+	Describe("RevokeToken", func() {
+		var adminToken string
+
+		BeforeEach(func() {
+			var err error
+			adminToken, err = warrant.NewClientsService(config).GetToken("admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("revokes the token with the given id", func() {
+			revokedToken, err := warrant.NewClientsService(config).GetToken("admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+
+			decodedToken, err := service.Decode(revokedToken)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = service.RevokeToken(decodedToken.JTI, adminToken)
+			Expect(err).NotTo(HaveOccurred())
+
+			response, err := service.CheckToken(revokedToken, "admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Active).To(BeFalse())
+
+			_, err = warrant.NewUsersService(config).List(warrant.Query{}, revokedToken)
+			Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+
+			_, err = warrant.NewUsersService(config).List(warrant.Query{}, adminToken)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an error when the token is unauthorized", func() {
+			userToken := fakeUAA.UserTokenFor("some-user-id", []string{"openid"}, []string{})
+
+			err := service.RevokeToken("some-token-id", userToken)
+			Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 		})
 	})
 })
@@ -0,0 +1,30 @@
+package warrant
+
+import "github.com/pivotal-cf-experimental/warrant/internal/network"
+
+// TraceFormat is the format in which tracing information is written to the
+// TraceWriter of a Config.
+type TraceFormat int
+
+const (
+	// TraceFormatText writes each request and response, including their
+	// headers and bodies, as lines of text. Credentials such as tokens,
+	// passwords, and client secrets are redacted unless TraceSecrets is set.
+	TraceFormatText TraceFormat = iota
+
+	// TraceFormatJSON writes a JSON object on a single line for each attempt
+	// of a request to UAA. Each object includes the "method", "path",
+	// "status", and "duration_ms" of the attempt, along with a
+	// "correlation_id" shared by all attempts of the same request. Request
+	// and response bodies are not included.
+	TraceFormatJSON
+)
+
+func (f TraceFormat) networkTraceFormat() network.TraceFormat {
+	switch f {
+	case TraceFormatJSON:
+		return network.TraceFormatJSON
+	default:
+		return network.TraceFormatText
+	}
+}
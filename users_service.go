@@ -1,6 +1,7 @@
 package warrant
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -15,14 +16,22 @@ import (
 type Query struct {
 	// Filter is a string representation of a filtering expression as specified in the SCIM spec.
 	Filter string
+	// Where is a filtering expression built using functions like Eq and And. When both Filter
+	// and Where are given, resources must match both expressions.
+	Where Filter
 	// SortBy is a string representation of what field to sort the users by.
 	SortBy string
+	// StartIndex is the 1-based index of the first resource to return. When zero, UAA starts
+	// at the first resource.
+	StartIndex int
+	// Count is the maximum number of resources to return in a single page. When zero, UAA
+	// applies its default page size.
+	Count int
 }
 
 // TODO: Verify a user
 // TODO: Query for user info
 // TODO: Convert user ids to names
-// TODO: Pagination for List
 // TODO: Patch
 
 // UsersService provides access to common user actions. Using this service, you can create, fetch,
@@ -41,10 +50,20 @@ func NewUsersService(config Config) UsersService {
 // Create will make a request to UAA to create a new user resource with the given username and email.
 // A token with the "scim.write" scope is required.
 func (us UsersService) Create(username, email, token string) (User, error) {
+	return us.CreateWithContext(context.Background(), username, email, token)
+}
+
+// CreateWithContext is like Create, but uses the given context for the request to UAA.
+func (us UsersService) CreateWithContext(ctx context.Context, username, email, token string) (User, error) {
+	authorization, err := tokenAuthorization(ctx, us.config, token)
+	if err != nil {
+		return User{}, err
+	}
+
 	resp, err := newNetworkClient(us.config).MakeRequest(network.Request{
 		Method:        "POST",
 		Path:          "/Users",
-		Authorization: network.NewTokenAuthorization(token),
+		Authorization: authorization,
 		Body: network.NewJSONRequestBody(documents.CreateUserRequest{
 			UserName: username,
 			Emails: []documents.Email{
@@ -52,6 +71,7 @@ func (us UsersService) Create(username, email, token string) (User, error) {
 			},
 		}),
 		AcceptableStatusCodes: []int{http.StatusCreated},
+		Context:               ctx,
 	})
 	if err != nil {
 		return User{}, translateError(err)
@@ -69,11 +89,22 @@ func (us UsersService) Create(username, email, token string) (User, error) {
 // Get will make a request to UAA to fetch the user with the matching id.
 // A token with the "scim.read" scope is required.
 func (us UsersService) Get(id, token string) (User, error) {
+	return us.GetWithContext(context.Background(), id, token)
+}
+
+// GetWithContext is like Get, but uses the given context for the request to UAA.
+func (us UsersService) GetWithContext(ctx context.Context, id, token string) (User, error) {
+	authorization, err := tokenAuthorization(ctx, us.config, token)
+	if err != nil {
+		return User{}, err
+	}
+
 	resp, err := newNetworkClient(us.config).MakeRequest(network.Request{
 		Method:                "GET",
 		Path:                  fmt.Sprintf("/Users/%s", id),
-		Authorization:         network.NewTokenAuthorization(token),
+		Authorization:         authorization,
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return User{}, translateError(err)
@@ -91,11 +122,49 @@ func (us UsersService) Get(id, token string) (User, error) {
 // Delete will make a request to UAA to delete the user resource with the matching id.
 // A token with the "scim.write" scope is required.
 func (us UsersService) Delete(id, token string) error {
-	_, err := newNetworkClient(us.config).MakeRequest(network.Request{
+	return us.DeleteWithContext(context.Background(), id, token)
+}
+
+// DeleteWithContext is like Delete, but uses the given context for the request to UAA.
+func (us UsersService) DeleteWithContext(ctx context.Context, id, token string) error {
+	authorization, err := tokenAuthorization(ctx, us.config, token)
+	if err != nil {
+		return err
+	}
+
+	_, err = newNetworkClient(us.config).MakeRequest(network.Request{
 		Method:                "DELETE",
 		Path:                  fmt.Sprintf("/Users/%s", id),
-		Authorization:         network.NewTokenAuthorization(token),
+		Authorization:         authorization,
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
+	})
+	if err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// RevokeTokens will make a request to UAA to revoke all of the tokens granted to the user with the
+// matching id. A token with the "tokens.revoke" scope or the "uaa.admin" authority is required.
+func (us UsersService) RevokeTokens(id string, token string) error {
+	return us.RevokeTokensWithContext(context.Background(), id, token)
+}
+
+// RevokeTokensWithContext is like RevokeTokens, but uses the given context for the request to UAA.
+func (us UsersService) RevokeTokensWithContext(ctx context.Context, id string, token string) error {
+	authorization, err := tokenAuthorization(ctx, us.config, token)
+	if err != nil {
+		return err
+	}
+
+	_, err = newNetworkClient(us.config).MakeRequest(network.Request{
+		Method:                "GET",
+		Path:                  fmt.Sprintf("/oauth/token/revoke/user/%s", id),
+		Authorization:         authorization,
+		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return translateError(err)
@@ -107,13 +176,24 @@ func (us UsersService) Delete(id, token string) error {
 // Update will make a request to UAA to update the matching user resource.
 // A token with the "scim.write" or "uaa.admin" scope is required.
 func (us UsersService) Update(user User, token string) (User, error) {
+	return us.UpdateWithContext(context.Background(), user, token)
+}
+
+// UpdateWithContext is like Update, but uses the given context for the request to UAA.
+func (us UsersService) UpdateWithContext(ctx context.Context, user User, token string) (User, error) {
+	authorization, err := tokenAuthorization(ctx, us.config, token)
+	if err != nil {
+		return User{}, err
+	}
+
 	resp, err := newNetworkClient(us.config).MakeRequest(network.Request{
 		Method:                "PUT",
 		Path:                  fmt.Sprintf("/Users/%s", user.ID),
-		Authorization:         network.NewTokenAuthorization(token),
+		Authorization:         authorization,
 		IfMatch:               strconv.Itoa(user.Version),
 		Body:                  network.NewJSONRequestBody(newUpdateUserDocumentFromUser(user)),
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return User{}, translateError(err)
@@ -131,14 +211,25 @@ func (us UsersService) Update(user User, token string) (User, error) {
 // SetPassword will make a request to UAA to set the password for the user with the matching id to the
 // given password value. A token with the "password.write" scope is required.
 func (us UsersService) SetPassword(id, password, token string) error {
-	_, err := newNetworkClient(us.config).MakeRequest(network.Request{
+	return us.SetPasswordWithContext(context.Background(), id, password, token)
+}
+
+// SetPasswordWithContext is like SetPassword, but uses the given context for the request to UAA.
+func (us UsersService) SetPasswordWithContext(ctx context.Context, id, password, token string) error {
+	authorization, err := tokenAuthorization(ctx, us.config, token)
+	if err != nil {
+		return err
+	}
+
+	_, err = newNetworkClient(us.config).MakeRequest(network.Request{
 		Method:        "PUT",
 		Path:          fmt.Sprintf("/Users/%s/password", id),
-		Authorization: network.NewTokenAuthorization(token),
+		Authorization: authorization,
 		Body: network.NewJSONRequestBody(documents.SetPasswordRequest{
 			Password: password,
 		}),
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return translateError(err)
@@ -151,15 +242,26 @@ func (us UsersService) SetPassword(id, password, token string) error {
 // to the given password value. The existing password for the user resource as well as a token for the
 // user is required.
 func (us UsersService) ChangePassword(id, oldPassword, password, token string) error {
-	_, err := newNetworkClient(us.config).MakeRequest(network.Request{
+	return us.ChangePasswordWithContext(context.Background(), id, oldPassword, password, token)
+}
+
+// ChangePasswordWithContext is like ChangePassword, but uses the given context for the request to UAA.
+func (us UsersService) ChangePasswordWithContext(ctx context.Context, id, oldPassword, password, token string) error {
+	authorization, err := tokenAuthorization(ctx, us.config, token)
+	if err != nil {
+		return err
+	}
+
+	_, err = newNetworkClient(us.config).MakeRequest(network.Request{
 		Method:        "PUT",
 		Path:          fmt.Sprintf("/Users/%s/password", id),
-		Authorization: network.NewTokenAuthorization(token),
+		Authorization: authorization,
 		Body: network.NewJSONRequestBody(documents.ChangePasswordRequest{
 			OldPassword: oldPassword,
 			Password:    password,
 		}),
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
 		return translateError(err)
@@ -171,6 +273,28 @@ func (us UsersService) ChangePassword(id, oldPassword, password, token string) e
 // GetToken will make a request to UAA to retrieve the token for the user matching the given username.
 // The user's password is required.
 func (us UsersService) GetToken(username, password string, client Client) (string, error) {
+	return us.GetTokenWithContext(context.Background(), username, password, client)
+}
+
+// GetTokenWithContext is like GetToken, but uses the given context for the request to UAA.
+func (us UsersService) GetTokenWithContext(ctx context.Context, username, password string, client Client) (string, error) {
+	response, err := us.GetTokenResponseWithContext(ctx, username, password, client)
+	if err != nil {
+		return "", err
+	}
+
+	return response.AccessToken, nil
+}
+
+// GetTokenResponse will make a request to UAA to retrieve the tokens for the user matching the
+// given username. Unlike GetToken, the full TokenResponse is returned, including the refresh token
+// when the client is authorized for the "refresh_token" grant type. The user's password is required.
+func (us UsersService) GetTokenResponse(username, password string, client Client) (TokenResponse, error) {
+	return us.GetTokenResponseWithContext(context.Background(), username, password, client)
+}
+
+// GetTokenResponseWithContext is like GetTokenResponse, but uses the given context for the request to UAA.
+func (us UsersService) GetTokenResponseWithContext(ctx context.Context, username, password string, client Client) (TokenResponse, error) {
 	req := network.Request{
 		Method:        "POST",
 		Path:          "/oauth/token",
@@ -184,49 +308,73 @@ func (us UsersService) GetToken(username, password string, client Client) (strin
 			"response_type": []string{"token"},
 		}),
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	}
 
 	resp, err := newNetworkClient(us.config).MakeRequest(req)
 	if err != nil {
-		return "", translateError(err)
+		return TokenResponse{}, translateError(err)
 	}
 
-	var responseBody struct {
-		AccessToken string `json:"access_token"`
-	}
-	err = json.Unmarshal(resp.Body, &responseBody)
+	var response documents.TokenResponse
+	err = json.Unmarshal(resp.Body, &response)
 	if err != nil {
-		return "", MalformedResponseError{err}
+		return TokenResponse{}, MalformedResponseError{err}
 	}
 
-	return responseBody.AccessToken, nil
+	return newTokenResponseFromDocument(response), nil
 }
 
 // List will make a request to UAA to retrieve all user resources matching the given query.
 // A token with the "scim.read" or "uaa.admin" scope is required.
 func (us UsersService) List(query Query, token string) ([]User, error) {
+	return us.ListWithContext(context.Background(), query, token)
+}
+
+// ListWithContext is like List, but uses the given context for the request to UAA.
+func (us UsersService) ListWithContext(ctx context.Context, query Query, token string) ([]User, error) {
+	userList, _, err := us.ListPageWithContext(ctx, query, token)
+	if err != nil {
+		return []User{}, err
+	}
+
+	return userList, nil
+}
+
+// ListPage will make a request to UAA to retrieve a single page of user resources matching the
+// given query. The returned Page describes where that page sits within the full set of results.
+// Use Iterate to walk every page of results.
+func (us UsersService) ListPage(query Query, token string) ([]User, Page, error) {
+	return us.ListPageWithContext(context.Background(), query, token)
+}
+
+// ListPageWithContext is like ListPage, but uses the given context for the request to UAA.
+func (us UsersService) ListPageWithContext(ctx context.Context, query Query, token string) ([]User, Page, error) {
 	requestPath := url.URL{
-		Path: "/Users",
-		RawQuery: url.Values{
-			"filter": []string{query.Filter},
-			"sortBy": []string{query.SortBy},
-		}.Encode(),
+		Path:     "/Users",
+		RawQuery: query.toValues().Encode(),
+	}
+
+	authorization, err := tokenAuthorization(ctx, us.config, token)
+	if err != nil {
+		return []User{}, Page{}, err
 	}
 
 	resp, err := newNetworkClient(us.config).MakeRequest(network.Request{
 		Method:                "GET",
 		Path:                  requestPath.String(),
-		Authorization:         network.NewTokenAuthorization(token),
+		Authorization:         authorization,
 		AcceptableStatusCodes: []int{http.StatusOK},
+		Context:               ctx,
 	})
 	if err != nil {
-		return []User{}, translateError(err)
+		return []User{}, Page{}, translateError(err)
 	}
 
 	var response documents.UserListResponse
 	err = json.Unmarshal(resp.Body, &response)
 	if err != nil {
-		return []User{}, MalformedResponseError{err}
+		return []User{}, Page{}, MalformedResponseError{err}
 	}
 
 	var userList []User
@@ -234,7 +382,24 @@ func (us UsersService) List(query Query, token string) ([]User, error) {
 		userList = append(userList, newUserFromResponse(us.config, userResponse))
 	}
 
-	return userList, err
+	return userList, newPage(response.StartIndex, response.ItemsPerPage, response.TotalResults), nil
+}
+
+// Iterate returns a UsersIterator that walks every page of user resources matching the given
+// query, making requests to UAA as each page is needed. Query.StartIndex and Query.Count, when set,
+// control where iteration begins and how many resources are fetched per request.
+func (us UsersService) Iterate(query Query, token string) *UsersIterator {
+	return us.IterateWithContext(context.Background(), query, token)
+}
+
+// IterateWithContext is like Iterate, but uses the given context for the requests to UAA.
+func (us UsersService) IterateWithContext(ctx context.Context, query Query, token string) *UsersIterator {
+	return &UsersIterator{
+		pager: newPager(ctx, query),
+		list: func(ctx context.Context, query Query) ([]User, Page, error) {
+			return us.ListPageWithContext(ctx, query, token)
+		},
+	}
 }
 
 func newUpdateUserDocumentFromUser(user User) documents.UpdateUserRequest {
@@ -1,9 +1,12 @@
 package warrant_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
 	"time"
 
 	"github.com/pivotal-cf-experimental/warrant"
@@ -61,7 +64,7 @@ var _ = Describe("UsersService", func() {
 		})
 
 		Context("when the client does not have the scim.write scope", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"scim"},
@@ -75,12 +78,12 @@ var _ = Describe("UsersService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.Create("created-user", "user@example.com", t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
 		Context("when the client does not have the scim audience", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"banana"},
@@ -94,7 +97,7 @@ var _ = Describe("UsersService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.Create("created-user", "user@example.com", t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
@@ -147,8 +150,28 @@ var _ = Describe("UsersService", func() {
 			Expect(user).To(Equal(createdUser))
 		})
 
+		Context("when the client has the uaa.admin scope", func() {
+			It("returns the user", func() {
+				c := warrant.Client{
+					ID:          "uaa-admin",
+					ResourceIDs: []string{"scim"},
+					Authorities: []string{"uaa.admin"},
+				}
+
+				err := clientsService.Create(c, "secret", token)
+				Expect(err).NotTo(HaveOccurred())
+
+				t, err := clientsService.GetToken(c.ID, "secret")
+				Expect(err).NotTo(HaveOccurred())
+
+				user, err := service.Get(createdUser.ID, t)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(user).To(Equal(createdUser))
+			})
+		})
+
 		Context("when the client does not have the scim.read scope", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"scim"},
@@ -162,12 +185,12 @@ var _ = Describe("UsersService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.Get(createdUser.ID, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
 		Context("when the client does not have the scim audience", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"banana"},
@@ -181,7 +204,7 @@ var _ = Describe("UsersService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.Get(createdUser.ID, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
@@ -226,7 +249,7 @@ var _ = Describe("UsersService", func() {
 		})
 
 		Context("when the client does not have the scim.write scope", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"scim"},
@@ -240,12 +263,12 @@ var _ = Describe("UsersService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				err = service.Delete(user.ID, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
 		Context("when the client does not have the scim audience", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"banana"},
@@ -259,7 +282,7 @@ var _ = Describe("UsersService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				err = service.Delete(user.ID, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
@@ -269,6 +292,54 @@ var _ = Describe("UsersService", func() {
 		})
 	})
 
+	Describe("RevokeTokens", func() {
+		var user, otherUser warrant.User
+
+		BeforeEach(func() {
+			var err error
+			user, err = service.Create("revoked-user", "user@example.com", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			otherUser, err = service.Create("other-user", "other@example.com", token)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("revokes the tokens granted to the user", func() {
+			userToken := fakeUAA.UserTokenFor(user.ID, []string{"scim.read"}, []string{"scim"})
+			otherUserToken := fakeUAA.UserTokenFor(otherUser.ID, []string{"scim.read"}, []string{"scim"})
+
+			err := service.RevokeTokens(user.ID, token)
+			Expect(err).NotTo(HaveOccurred())
+
+			tokensService := warrant.NewTokensService(config)
+
+			response, err := tokensService.CheckToken(userToken, "admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Active).To(BeFalse())
+
+			response, err = tokensService.CheckToken(otherUserToken, "admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Active).To(BeTrue())
+
+			newUserToken := fakeUAA.UserTokenFor(user.ID, []string{"scim.read"}, []string{"scim"})
+			response, err = tokensService.CheckToken(newUserToken, "admin", "admin")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Active).To(BeTrue())
+		})
+
+		It("returns an error when the user does not exist", func() {
+			err := service.RevokeTokens("non-existent-user-guid", token)
+			Expect(err).To(BeAssignableToTypeOf(warrant.NotFoundError{}))
+		})
+
+		It("returns an error when the token is unauthorized", func() {
+			userToken := fakeUAA.UserTokenFor(user.ID, []string{"scim.read"}, []string{"scim"})
+
+			err := service.RevokeTokens(otherUser.ID, userToken)
+			Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
+		})
+	})
+
 	Describe("Update", func() {
 		var user warrant.User
 
@@ -309,7 +380,7 @@ var _ = Describe("UsersService", func() {
 		})
 
 		Context("when the client does not have the scim.write scope", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"scim"},
@@ -323,12 +394,12 @@ var _ = Describe("UsersService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.Update(user, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
 		Context("when the client does not have the scim audience", func() {
-			It("returns an unauthorized error", func() {
+			It("returns a forbidden error", func() {
 				c := warrant.Client{
 					ID:          "unauthorized",
 					ResourceIDs: []string{"banana"},
@@ -342,7 +413,7 @@ var _ = Describe("UsersService", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = service.Update(user, t)
-				Expect(err).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+				Expect(err).To(BeAssignableToTypeOf(warrant.ForbiddenError{}))
 			})
 		})
 
@@ -598,6 +669,51 @@ var _ = Describe("UsersService", func() {
 		})
 	})
 
+	Describe("GetTokenResponse", func() {
+		var client warrant.Client
+
+		BeforeEach(func() {
+			user, err := service.Create("username", "user@example.com", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = service.SetPassword(user.ID, "password", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			client = warrant.Client{
+				ID:                   "some-client-id",
+				Scope:                []string{"openid"},
+				AuthorizedGrantTypes: []string{"password", "refresh_token"},
+			}
+			err = warrant.NewClientsService(config).Create(client, "", token)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the access and refresh tokens for the user", func() {
+			response, err := service.GetTokenResponse("username", "password", client)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.AccessToken).NotTo(BeEmpty())
+			Expect(response.RefreshToken).NotTo(BeEmpty())
+			Expect(response.Scopes).To(Equal([]string{"openid"}))
+			Expect(response.Expiry).To(BeTemporally(">", time.Now()))
+
+			decodedToken, err := warrant.NewTokensService(config).Decode(response.AccessToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedToken.Issuer).To(Equal(fakeUAA.URL() + "/oauth/token"))
+		})
+
+		It("does not return a refresh token when the client is not authorized for the refresh_token grant", func() {
+			client.ID = "no-refresh-client"
+			client.AuthorizedGrantTypes = []string{"password"}
+			err := warrant.NewClientsService(config).Create(client, "", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			response, err := service.GetTokenResponse("username", "password", client)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.AccessToken).NotTo(BeEmpty())
+			Expect(response.RefreshToken).To(BeEmpty())
+		})
+	})
+
 	Describe("List", func() {
 		var (
 			user      warrant.User
@@ -652,6 +768,64 @@ var _ = Describe("UsersService", func() {
 			Expect(users[0].ID).To(Equal(user.ID))
 		})
 
+		It("finds users using the SCIM filter operators", func() {
+			users, err := service.List(warrant.Query{
+				Filter: `userName co "NAM"`,
+			}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+			Expect(users[0].ID).To(Equal(user.ID))
+
+			users, err = service.List(warrant.Query{
+				Filter: `emails.value eq "abc@example.com"`,
+			}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+			Expect(users[0].ID).To(Equal(otherUser.ID))
+
+			users, err = service.List(warrant.Query{
+				Filter: `origin eq "uaa" and userName sw "oth"`,
+			}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+			Expect(users[0].ID).To(Equal(otherUser.ID))
+
+			users, err = service.List(warrant.Query{
+				Filter: `userName eq "username" or userName eq "other"`,
+			}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(2))
+
+			users, err = service.List(warrant.Query{
+				Filter: `emails pr`,
+			}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(2))
+		})
+
+		It("finds users that match a filter built with the filter builder", func() {
+			quotedUser, err := service.Create(`o"brien`, "obrien@example.com", token)
+			Expect(err).NotTo(HaveOccurred())
+
+			users, err := service.List(warrant.Query{
+				Where: warrant.Or(warrant.Eq("userName", `o"brien`), warrant.Eq("id", user.ID)),
+			}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(2))
+			Expect(users[0].ID).To(Equal(user.ID))
+			Expect(users[1].ID).To(Equal(quotedUser.ID))
+		})
+
+		It("finds users that match both the raw filter and the filter builder", func() {
+			users, err := service.List(warrant.Query{
+				Filter: `userName eq "username" or userName eq "other"`,
+				Where:  warrant.Sw("emails.value", "abc"),
+			}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+			Expect(users[0].ID).To(Equal(otherUser.ID))
+		})
+
 		It("defaults to sorting users by date created", func() {
 			users, err := service.List(warrant.Query{}, token)
 			Expect(err).NotTo(HaveOccurred())
@@ -681,6 +855,14 @@ var _ = Describe("UsersService", func() {
 				Expect(err.Error()).To(Equal(`bad request: {"error_description":"Invalid filter expression: [invalid-parameter eq '` + user.ID + `']","error":"scim"}`))
 			})
 
+			It("returns an error when the filter expression cannot be parsed", func() {
+				_, err := service.List(warrant.Query{
+					Filter: `userName eq "username`,
+				}, token)
+				Expect(err).To(BeAssignableToTypeOf(warrant.BadRequestError{}))
+				Expect(err).To(MatchError(ContainSubstring(`"error":"scim"`)))
+			})
+
 			It("returns an error when the JSON is malformed", func() {
 				malformedJSONServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 					w.Write([]byte("this is not JSON"))
@@ -699,4 +881,140 @@ var _ = Describe("UsersService", func() {
 			})
 		})
 	})
+
+	Describe("ListPage", func() {
+		var users []warrant.User
+
+		BeforeEach(func() {
+			for _, name := range []string{"first", "second", "third"} {
+				user, err := service.Create(name, name+"@example.com", token)
+				Expect(err).NotTo(HaveOccurred())
+
+				users = append(users, user)
+			}
+		})
+
+		It("returns the requested page along with its metadata", func() {
+			page, metadata, err := service.ListPage(warrant.Query{
+				StartIndex: 2,
+				Count:      1,
+			}, token)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(page).To(HaveLen(1))
+			Expect(page[0].ID).To(Equal(users[1].ID))
+			Expect(metadata).To(Equal(warrant.Page{
+				StartIndex:   2,
+				ItemsPerPage: 1,
+				TotalResults: 3,
+			}))
+		})
+
+		It("returns an empty page when the start index is beyond the results", func() {
+			page, metadata, err := service.ListPage(warrant.Query{
+				StartIndex: 10,
+			}, token)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(page).To(BeEmpty())
+			Expect(metadata.TotalResults).To(Equal(3))
+		})
+	})
+
+	Describe("Iterate", func() {
+		var users []warrant.User
+
+		BeforeEach(func() {
+			users = nil
+			for i := 0; i < 5; i++ {
+				name := fmt.Sprintf("user-%d", i)
+				user, err := service.Create(name, name+"@example.com", token)
+				Expect(err).NotTo(HaveOccurred())
+
+				users = append(users, user)
+			}
+		})
+
+		It("walks every page of users", func() {
+			iterator := service.Iterate(warrant.Query{Count: 2}, token)
+
+			var ids []string
+			for iterator.Next() {
+				ids = append(ids, iterator.User().ID)
+			}
+			Expect(iterator.Err()).NotTo(HaveOccurred())
+
+			Expect(ids).To(Equal([]string{
+				users[0].ID,
+				users[1].ID,
+				users[2].ID,
+				users[3].ID,
+				users[4].ID,
+			}))
+		})
+
+		It("begins iterating at the given start index", func() {
+			iterator := service.Iterate(warrant.Query{StartIndex: 4, Count: 1}, token)
+
+			var ids []string
+			for iterator.Next() {
+				ids = append(ids, iterator.User().ID)
+			}
+			Expect(iterator.Err()).NotTo(HaveOccurred())
+
+			Expect(ids).To(Equal([]string{users[3].ID, users[4].ID}))
+		})
+
+		It("makes one request per page", func() {
+			var requests int
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				requests++
+				target, err := url.Parse(fakeUAA.URL())
+				Expect(err).NotTo(HaveOccurred())
+				httputil.NewSingleHostReverseProxy(target).ServeHTTP(w, req)
+			}))
+			defer proxy.Close()
+
+			service = warrant.NewUsersService(warrant.Config{
+				Host:          proxy.URL,
+				SkipVerifySSL: true,
+				TraceWriter:   TraceWriter,
+			})
+
+			iterator := service.Iterate(warrant.Query{Count: 2}, token)
+			for iterator.Next() {
+			}
+			Expect(iterator.Err()).NotTo(HaveOccurred())
+			Expect(requests).To(Equal(3))
+		})
+
+		It("stops and reports the error when a page cannot be fetched", func() {
+			iterator := service.Iterate(warrant.Query{}, "invalid-token")
+
+			Expect(iterator.Next()).To(BeFalse())
+			Expect(iterator.Err()).To(BeAssignableToTypeOf(warrant.UnauthorizedError{}))
+			Expect(iterator.Next()).To(BeFalse())
+		})
+	})
+
+	Describe("CreateWithContext", func() {
+		It("creates a new user", func() {
+			user, err := service.CreateWithContext(context.Background(), "created-user", "user@example.com", token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(user.UserName).To(Equal("created-user"))
+		})
+
+		It("does not create the user when the context has been canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := service.CreateWithContext(ctx, "created-user", "user@example.com", token)
+			Expect(err).To(BeAssignableToTypeOf(warrant.UnknownError{}))
+			Expect(err.Error()).To(ContainSubstring("context canceled"))
+
+			users, err := service.List(warrant.Query{}, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(BeEmpty())
+		})
+	})
 })
@@ -2,6 +2,7 @@ package warrant
 
 import (
 	"io"
+	"net/http"
 
 	"github.com/pivotal-cf-experimental/warrant/internal/network"
 )
@@ -19,9 +20,62 @@ type Config struct {
 	// certificate of the UAA service should those requests be communicated over HTTPS.
 	SkipVerifySSL bool
 
+	// CACerts is a PEM encoded bundle of the certificate authorities trusted to sign the SSL
+	// certificate of the UAA service. When CACerts or CACertsFile is set, these authorities are
+	// trusted in place of those provided by the operating system.
+	CACerts []byte
+
+	// CACertsFile is the path to a PEM encoded bundle of certificate authorities, trusted in
+	// addition to any given in CACerts.
+	CACertsFile string
+
+	// ClientCert and ClientKey are the PEM encoded certificate and private key presented to a UAA
+	// service that requires mutual TLS.
+	ClientCert []byte
+	ClientKey  []byte
+
+	// ClientCertFile and ClientKeyFile are the paths to a PEM encoded certificate and private key,
+	// which may be given in place of ClientCert and ClientKey. These files, and CACertsFile, are
+	// read again when their size or modification time changes, so that certificates rotated in
+	// place are used without a restart.
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// MinTLSVersion is the minimum version of TLS that the HTTP client will negotiate with the UAA
+	// service, such as tls.VersionTLS12. The default of the crypto/tls package is used when this
+	// value is zero.
+	MinTLSVersion uint16
+
+	// Transport is an optional http.RoundTripper used to make requests to the UAA service, in
+	// place of the transports shared by all clients of this library. The TLS settings above are
+	// ignored when a Transport is given.
+	Transport http.RoundTripper
+
+	// Interceptors are called, in order, for every request made to the UAA service. The first
+	// Interceptor is the first to see each request, and the last to see each response.
+	Interceptors []Interceptor
+
+	// Retry describes how requests to the UAA service are retried when they fail with a transient
+	// error. Requests are attempted once when this field is not set.
+	Retry RetryPolicy
+
 	// TraceWriter is an io.Writer to which tracing information can be written. This information
-	// includes the outgoing request and the incoming responses from UAA.
+	// includes the outgoing request and the incoming responses from UAA, with any credentials that
+	// they contain redacted.
 	TraceWriter io.Writer
+
+	// TraceFormat is the format in which tracing information is written to the TraceWriter. This
+	// value defaults to TraceFormatText.
+	TraceFormat TraceFormat
+
+	// TraceSecrets disables the redaction of credentials, such as tokens, passwords, and client
+	// secrets, from the tracing information written to the TraceWriter. It should only be set when
+	// debugging against a UAA service that holds no real credentials.
+	TraceSecrets bool
+
+	// TokenSource is an optional source of tokens. When set, the services will use it to
+	// retrieve a token for any method that is called with an empty token argument.
+	TokenSource TokenSource
 }
 
 // Warrant provices access to the users, clients, groups, and tokens services provided by this library.
@@ -55,8 +109,29 @@ func New(config Config) Warrant {
 
 func newNetworkClient(config Config) network.Client {
 	return network.NewClient(network.Config{
-		Host:          config.Host,
-		SkipVerifySSL: config.SkipVerifySSL,
-		TraceWriter:   config.TraceWriter,
+		Host:           config.Host,
+		SkipVerifySSL:  config.SkipVerifySSL,
+		CACerts:        config.CACerts,
+		CACertsFile:    config.CACertsFile,
+		ClientCert:     config.ClientCert,
+		ClientKey:      config.ClientKey,
+		ClientCertFile: config.ClientCertFile,
+		ClientKeyFile:  config.ClientKeyFile,
+		MinTLSVersion:  config.MinTLSVersion,
+		Transport:      config.Transport,
+		Interceptors:   networkInterceptors(config.Interceptors),
+		Retry:          config.Retry.networkRetryPolicy(),
+		TraceWriter:    config.TraceWriter,
+		TraceFormat:    config.TraceFormat.networkTraceFormat(),
+		TraceSecrets:   config.TraceSecrets,
 	})
 }
+
+func networkInterceptors(interceptors []Interceptor) []network.Interceptor {
+	var result []network.Interceptor
+	for _, interceptor := range interceptors {
+		result = append(result, interceptor)
+	}
+
+	return result
+}
@@ -1,12 +1,25 @@
 package warrant_test
 
 import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
 	"github.com/pivotal-cf-experimental/warrant"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
 var _ = Describe("Warrant", func() {
 	var client warrant.Warrant
 
@@ -29,4 +42,117 @@ var _ = Describe("Warrant", func() {
 	It("has a groups service", func() {
 		Expect(client.Groups).To(BeAssignableToTypeOf(warrant.GroupsService{}))
 	})
+
+	It("passes the requests of its services through the configured interceptors", func() {
+		var paths []string
+		client = warrant.New(warrant.Config{
+			Host:          fakeUAA.URL(),
+			SkipVerifySSL: true,
+			TraceWriter:   TraceWriter,
+			Interceptors: []warrant.Interceptor{
+				warrant.InterceptorFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+					paths = append(paths, req.URL.Path)
+					return next.RoundTrip(req)
+				}),
+			},
+		})
+
+		token, err := client.Clients.GetToken("admin", "admin")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.Users.List(warrant.Query{}, token)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(paths).To(Equal([]string{"/oauth/token", "/Users"}))
+	})
+
+	It("makes the requests of its services using the configured transport", func() {
+		var transportUsed bool
+		transport := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+		defer transport.CloseIdleConnections()
+
+		client = warrant.New(warrant.Config{
+			Host:        fakeUAA.URL(),
+			TraceWriter: TraceWriter,
+			Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				transportUsed = true
+				return transport.RoundTrip(req)
+			}),
+		})
+
+		_, err := client.Clients.GetToken("admin", "admin")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(transportUsed).To(BeTrue())
+	})
+
+	It("retries the requests of its services according to the configured policy", func() {
+		var attempts int
+		flakyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			attempts++
+			if attempts == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			w.Write([]byte(`{"keys": []}`))
+		}))
+		defer flakyServer.Close()
+
+		client = warrant.New(warrant.Config{
+			Host:        flakyServer.URL,
+			TraceWriter: TraceWriter,
+			Retry: warrant.RetryPolicy{
+				MaxAttempts:    2,
+				InitialBackoff: time.Millisecond,
+			},
+		})
+
+		_, err := client.Tokens.GetSigningKeys()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attempts).To(Equal(2))
+	})
+
+	It("traces the requests of its services in the configured format", func() {
+		trace := bytes.NewBuffer([]byte{})
+		client = warrant.New(warrant.Config{
+			Host:          fakeUAA.URL(),
+			SkipVerifySSL: true,
+			TraceWriter:   trace,
+			TraceFormat:   warrant.TraceFormatJSON,
+		})
+
+		_, err := client.Clients.GetToken("admin", "admin")
+		Expect(err).NotTo(HaveOccurred())
+
+		var entry map[string]interface{}
+		Expect(json.Unmarshal(trace.Bytes(), &entry)).To(Succeed())
+		Expect(entry).To(HaveKeyWithValue("method", "POST"))
+		Expect(entry).To(HaveKeyWithValue("path", "/oauth/token"))
+		Expect(entry).To(HaveKeyWithValue("status", BeNumerically("==", http.StatusOK)))
+	})
+
+	It("redacts credentials from the trace of its services", func() {
+		trace := bytes.NewBuffer([]byte{})
+		client = warrant.New(warrant.Config{
+			Host:          fakeUAA.URL(),
+			SkipVerifySSL: true,
+			TraceWriter:   trace,
+		})
+
+		token, err := client.Clients.GetToken("admin", "admin")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trace.String()).To(ContainSubstring("REQUEST: POST"))
+		Expect(trace.String()).NotTo(ContainSubstring(token))
+	})
+
+	It("configures the TLS settings of its services", func() {
+		client = warrant.New(warrant.Config{
+			Host:    fakeUAA.URL(),
+			CACerts: []byte("not a certificate"),
+		})
+
+		_, err := client.Clients.GetToken("admin", "admin")
+		Expect(err).To(BeAssignableToTypeOf(warrant.UnknownError{}))
+		Expect(err).To(MatchError(ContainSubstring("CA certificates do not contain any PEM encoded certificates")))
+	})
 })